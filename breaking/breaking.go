@@ -0,0 +1,363 @@
+// Package breaking finds the changes between two versions of a schema that break clients built
+// from the old version, or documents they wrote: changes to how a value is stored in Firestore.
+//
+// Schemas are compared in their serialized form (see package ir), so the old version can be a
+// dump kept from an earlier build instead of its source.
+package breaking
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/visor-tax/firemodel"
+	"github.com/visor-tax/firemodel/ir"
+)
+
+// Rule is a kind of breaking change.
+type Rule string
+
+const (
+	// ModelRemoved is reported for removed models.
+	ModelRemoved Rule = "model_removed"
+	// ModelPathChanged is reported when the firestore.path option of a model changes.
+	ModelPathChanged Rule = "model_path_changed"
+	// CollectionRenamed is reported when a collection nested in a model is renamed or removed.
+	CollectionRenamed Rule = "collection_renamed"
+	// FieldRemoved is reported for removed fields.
+	FieldRemoved Rule = "field_removed"
+	// FieldTypeChanged is reported when the type of a field changes.
+	FieldTypeChanged Rule = "field_type_changed"
+	// FieldWireNameChanged is reported when the name a field is stored under changes.
+	FieldWireNameChanged Rule = "field_wire_name_changed"
+	// FieldRequired is reported when a field becomes required.
+	FieldRequired Rule = "field_required"
+	// FieldNotNullable is reported when a nullable field stops being nullable.
+	FieldNotNullable Rule = "field_not_nullable"
+	// EnumValueRemoved is reported for removed enum values.
+	EnumValueRemoved Rule = "enum_value_removed"
+	// EnumValueChanged is reported when the stored value of an enum value changes.
+	EnumValueChanged Rule = "enum_value_changed"
+	// UnionDiscriminatorChanged is reported when the discriminator of a union changes.
+	UnionDiscriminatorChanged Rule = "union_discriminator_changed"
+	// UnionMemberRemoved is reported for removed union members.
+	UnionMemberRemoved Rule = "union_member_removed"
+)
+
+// Off is the severity of rules that are not checked.
+const Off firemodel.Severity = -1
+
+// Defaults are the severities of the rules that are not set in a Config. Removing a model or a
+// field only breaks the clients that use it, so they are warnings.
+var Defaults = map[Rule]firemodel.Severity{
+	ModelRemoved:              firemodel.SeverityWarning,
+	ModelPathChanged:          firemodel.SeverityError,
+	CollectionRenamed:         firemodel.SeverityError,
+	FieldRemoved:              firemodel.SeverityWarning,
+	FieldTypeChanged:          firemodel.SeverityError,
+	FieldWireNameChanged:      firemodel.SeverityError,
+	FieldRequired:             firemodel.SeverityError,
+	FieldNotNullable:          firemodel.SeverityError,
+	EnumValueRemoved:          firemodel.SeverityError,
+	EnumValueChanged:          firemodel.SeverityError,
+	UnionDiscriminatorChanged: firemodel.SeverityError,
+	UnionMemberRemoved:        firemodel.SeverityError,
+}
+
+// Config sets the severity of rules, overriding Defaults.
+type Config map[Rule]firemodel.Severity
+
+// ParseConfig parses rule severities keyed by rule name, e.g. {"field_removed": "error"}. The
+// severities are "error", "warning" and "off".
+func ParseConfig(severities map[string]string) (Config, error) {
+	config := Config{}
+	for name, value := range severities {
+		rule := Rule(name)
+		if _, ok := Defaults[rule]; !ok {
+			return nil, errors.Errorf("firemodel: unknown breaking change rule %s", name)
+		}
+		switch value {
+		case "error":
+			config[rule] = firemodel.SeverityError
+		case "warning":
+			config[rule] = firemodel.SeverityWarning
+		case "off":
+			config[rule] = Off
+		default:
+			return nil, errors.Errorf("firemodel: invalid severity %q for rule %s; use error, warning or off", value, name)
+		}
+	}
+	return config, nil
+}
+
+func (c Config) severity(rule Rule) firemodel.Severity {
+	if severity, ok := c[rule]; ok {
+		return severity
+	}
+	return Defaults[rule]
+}
+
+// Compare returns the breaking changes from schema old to schema new, as diagnostics at the
+// position of the changed declaration in new, or of the removed declaration in old. Each message
+// ends with the name of its rule.
+func Compare(old, new *ir.Schema, config Config) firemodel.Diagnostics {
+	c := &comparer{config: config}
+	for _, oldModel := range old.Models {
+		newModel := findModel(new, oldModel.Package, oldModel.Name)
+		if newModel == nil {
+			c.report(ModelRemoved, oldModel.Pos, "model %s was removed", qualified(oldModel.Package, oldModel.Name))
+			continue
+		}
+		c.compareModel(oldModel, newModel)
+	}
+	for _, oldStruct := range old.Structs {
+		for _, newStruct := range new.Structs {
+			if newStruct.Package == oldStruct.Package && newStruct.Name == oldStruct.Name {
+				c.compareFields(qualified(newStruct.Package, newStruct.Name), oldStruct.Fields, newStruct.Fields)
+			}
+		}
+	}
+	for _, oldEnum := range old.Enums {
+		for _, newEnum := range new.Enums {
+			if newEnum.Package == oldEnum.Package && newEnum.Name == oldEnum.Name {
+				c.compareEnum(oldEnum, newEnum)
+			}
+		}
+	}
+	for _, oldUnion := range old.Unions {
+		for _, newUnion := range new.Unions {
+			if newUnion.Package == oldUnion.Package && newUnion.Name == oldUnion.Name {
+				c.compareUnion(oldUnion, newUnion)
+			}
+		}
+	}
+	sort.SliceStable(c.diagnostics, func(i, j int) bool {
+		a, b := c.diagnostics[i].Pos, c.diagnostics[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return c.diagnostics
+}
+
+type comparer struct {
+	config      Config
+	diagnostics firemodel.Diagnostics
+}
+
+func (c *comparer) report(rule Rule, pos ir.Position, format string, args ...interface{}) {
+	severity := c.config.severity(rule)
+	if severity == Off {
+		return
+	}
+	c.diagnostics = append(c.diagnostics, &firemodel.Diagnostic{
+		Pos:      firemodel.Position{Filename: pos.File, Line: pos.Line, Column: pos.Column},
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...) + fmt.Sprintf(" (%s)", rule),
+	})
+}
+
+func (c *comparer) compareModel(old, new *ir.Model) {
+	name := qualified(new.Package, new.Name)
+	if oldPath, newPath := old.Options["firestore"]["path"], new.Options["firestore"]["path"]; oldPath != newPath {
+		c.report(ModelPathChanged, new.Pos, "the path of model %s changed from %q to %q", name, oldPath, newPath)
+	}
+	for _, oldCollection := range old.Collections {
+		var renamed *ir.Collection
+		found := false
+		for _, newCollection := range new.Collections {
+			if newCollection.Name == oldCollection.Name {
+				found = true
+			} else if newCollection.Model == oldCollection.Model {
+				renamed = newCollection
+			}
+		}
+		switch {
+		case found:
+		case renamed != nil:
+			c.report(CollectionRenamed, renamed.Pos, "collection %s of model %s was renamed to %s", oldCollection.Name, name, renamed.Name)
+		default:
+			c.report(CollectionRenamed, new.Pos, "collection %s of model %s was removed", oldCollection.Name, name)
+		}
+	}
+	c.compareFields(name, old.Fields, new.Fields)
+}
+
+// compareFields compares the fields of the model or struct named owner. Fields are matched by the
+// name they are stored under, so renaming a field in the schema while keeping its stored name
+// doesn't break anything.
+func (c *comparer) compareFields(owner string, old, new []*ir.Field) {
+	for _, oldField := range old {
+		if oldField.Origin != nil && inherits(new, oldField) {
+			// Compared with the fields of the struct it was inherited from.
+			continue
+		}
+		newField, ok := c.matchField(owner, oldField, new)
+		if !ok {
+			c.report(FieldRemoved, oldField.Pos, "field %s.%s was removed", owner, oldField.Name)
+			continue
+		}
+		if newField.Presence == "required" && oldField.Presence != "required" {
+			c.report(FieldRequired, newField.Pos, "field %s.%s is now required", owner, newField.Name)
+		}
+	}
+}
+
+// inherits returns true if fields include field, inherited from the same struct.
+func inherits(fields []*ir.Field, field *ir.Field) bool {
+	for _, other := range fields {
+		if other.Name == field.Name && other.Origin != nil && *other.Origin == *field.Origin {
+			return true
+		}
+	}
+	return false
+}
+
+// matchField finds the field in fields that stores old, or failing that, has its name, in which
+// case its stored name changed. It reports the changes to the field, and returns false if there
+// is none.
+func (c *comparer) matchField(owner string, old *ir.Field, fields []*ir.Field) (*ir.Field, bool) {
+	var match *ir.Field
+	for _, field := range fields {
+		if field.WireName == old.WireName {
+			match = field
+		}
+	}
+	if match == nil {
+		match = findField(fields, old.Name)
+		if match == nil {
+			return nil, false
+		}
+		c.report(FieldWireNameChanged, match.Pos, "field %s.%s is now stored as %q instead of %q", owner, match.Name, match.WireName, old.WireName)
+	}
+
+	name := owner + "." + match.Name
+	if !sameType(old.Type, match.Type) {
+		c.report(FieldTypeChanged, match.Pos, "the type of field %s changed from %s to %s", name, old.Type, match.Type)
+	}
+	if old.Nullable && !match.Nullable {
+		c.report(FieldNotNullable, match.Pos, "field %s is no longer nullable", name)
+	}
+	return match, true
+}
+
+// compareEnum compares the values of an enum. Values are matched by name, but a value that was
+// renamed and kept its stored value, or that was removed and whose stored value is now an alias of
+// another value, still decodes. So must the aliases of the old values.
+func (c *comparer) compareEnum(old, new *ir.Enum) {
+	name := qualified(new.Package, new.Name)
+	for _, oldValue := range old.Values {
+		var newValue *ir.EnumValue
+		for _, value := range new.Values {
+			if value.Name == oldValue.Name {
+				newValue = value
+			}
+		}
+		switch {
+		case newValue != nil && decodes(newValue, oldValue.WireValue):
+		case newValue != nil:
+			c.report(EnumValueChanged, newValue.Pos, "value %s of enum %s is now stored as %s instead of %s", newValue.Name, name, format(newValue.WireValue), format(oldValue.WireValue))
+		case !decodesAny(new.Values, oldValue.WireValue):
+			c.report(EnumValueRemoved, oldValue.Pos, "value %s of enum %s was removed", oldValue.Name, name)
+			continue
+		}
+		// Documents may store the value as one of its aliases too.
+		for _, alias := range oldValue.Aliases {
+			switch {
+			case newValue != nil && !decodes(newValue, alias):
+				c.report(EnumValueChanged, newValue.Pos, "value %s of enum %s no longer decodes %s", newValue.Name, name, format(alias))
+			case newValue == nil && !decodesAny(new.Values, alias):
+				c.report(EnumValueRemoved, oldValue.Pos, "alias %s of the removed value %s of enum %s no longer decodes", format(alias), oldValue.Name, name)
+			}
+		}
+	}
+}
+
+func (c *comparer) compareUnion(old, new *ir.Union) {
+	name := qualified(new.Package, new.Name)
+	if old.Discriminator != new.Discriminator {
+		c.report(UnionDiscriminatorChanged, new.Pos, "the discriminator of union %s changed from %q to %q", name, old.Discriminator, new.Discriminator)
+	}
+	for _, oldMember := range old.Members {
+		if _, ok := c.matchField(name, oldMember, new.Members); !ok {
+			c.report(UnionMemberRemoved, oldMember.Pos, "member %s of union %s was removed", oldMember.Name, name)
+		}
+	}
+}
+
+// sameType returns true if values of type a are stored like values of type b. Structs, enums and
+// unions must be the same declaration, whose changes are compared separately.
+func sameType(a, b *ir.Type) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Kind != b.Kind {
+		return false
+	}
+	switch a.Kind {
+	case ir.Reference, ir.StructRef, ir.EnumRef, ir.UnionRef:
+		return reflect.DeepEqual(a.Ref, b.Ref)
+	case ir.Array:
+		return sameType(a.Items, b.Items)
+	case ir.Map:
+		return sameType(a.Key, b.Key) && sameType(a.Items, b.Items)
+	}
+	return true
+}
+
+// decodes returns true if the stored enum value decodes to value. Integers decoded from a dump
+// are float64s, so values are compared as they are formatted.
+func decodes(value *ir.EnumValue, stored interface{}) bool {
+	if format(value.WireValue) == format(stored) {
+		return true
+	}
+	for _, alias := range value.Aliases {
+		if format(alias) == format(stored) {
+			return true
+		}
+	}
+	return false
+}
+
+func decodesAny(values []*ir.EnumValue, stored interface{}) bool {
+	for _, value := range values {
+		if decodes(value, stored) {
+			return true
+		}
+	}
+	return false
+}
+
+func format(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprint(value)
+}
+
+func findModel(schema *ir.Schema, pkg, name string) *ir.Model {
+	for _, model := range schema.Models {
+		if model.Package == pkg && model.Name == name {
+			return model
+		}
+	}
+	return nil
+}
+
+func findField(fields []*ir.Field, name string) *ir.Field {
+	for _, field := range fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	return nil
+}
+
+func qualified(pkg, name string) string {
+	return ir.Ref{Package: pkg, Name: name}.String()
+}
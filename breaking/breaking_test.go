@@ -0,0 +1,99 @@
+package breaking_test
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/visor-tax/firemodel"
+	"github.com/visor-tax/firemodel/breaking"
+	"github.com/visor-tax/firemodel/ir"
+	"gotest.tools/assert"
+)
+
+var (
+	oldPath = filepath.Join("..", "testfixtures", "breaking", "old.firemodel")
+	newPath = filepath.Join("..", "testfixtures", "breaking", "new.firemodel")
+)
+
+func load(t *testing.T, path string) *ir.Schema {
+	schema, err := firemodel.ParseSchemaFiles(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ir.FromSchema(schema)
+}
+
+func messages(diagnostics firemodel.Diagnostics) []string {
+	out := make([]string, len(diagnostics))
+	for idx, d := range diagnostics {
+		out[idx] = d.Error()
+	}
+	return out
+}
+
+func TestCompare(t *testing.T) {
+	got := breaking.Compare(load(t, oldPath), load(t, newPath), nil)
+	assert.DeepEqual(t, messages(got), []string{
+		newPath + `:1:7: error: the path of model User changed from "users/{user_id}" to "accounts/{user_id}" (model_path_changed)`,
+		newPath + `:5:3: error: the type of field User.age changed from integer to string (field_type_changed)`,
+		newPath + `:6:3: error: field User.nickname is now required (field_required)`,
+		newPath + `:7:3: error: field User.deleted_at is no longer nullable (field_not_nullable)`,
+		newPath + `:8:3: error: field User.legacy is now stored as "legacy" instead of "LEGACY" (field_wire_name_changed)`,
+		newPath + `:12:3: error: the type of field User.tags changed from array<string> to array<integer> (field_type_changed)`,
+		newPath + `:15:3: error: collection returns of model User was renamed to refunds (collection_renamed)`,
+		newPath + `:29:3: error: the type of field Cheque.number changed from integer to string (field_type_changed)`,
+		newPath + `:32:7: error: the discriminator of union Payment changed from "type" to "kind" (union_discriminator_changed)`,
+		newPath + `:40:3: error: value suspended of enum Status is now stored as "SUSPENDED" instead of "S" (enum_value_changed)`,
+		newPath + `:47:3: error: value high of enum Priority is now stored as 3 instead of 2 (enum_value_changed)`,
+		newPath + `:53:3: error: the type of field Settings.method changed from Card to Cheque (field_type_changed)`,
+		newPath + `:54:3: error: the type of field Settings.level changed from Priority to Status (field_type_changed)`,
+		newPath + `:55:3: error: the type of field Settings.history changed from array<Card> to array<Cheque> (field_type_changed)`,
+		newPath + `:69:3: error: value north of enum Direction no longer decodes "NORTH" (enum_value_changed)`,
+		newPath + `:70:3: error: value south of enum Direction no longer decodes "SOUTH" (enum_value_changed)`,
+		oldPath + `:6:3: warning: field User.email was removed (field_removed)`,
+		oldPath + `:25:7: warning: model Draft was removed (model_removed)`,
+		oldPath + `:41:3: error: member cheque of union Payment was removed (union_member_removed)`,
+		oldPath + `:48:3: error: value deleted of enum Status was removed (enum_value_removed)`,
+		oldPath + `:66:3: warning: field Doc.owner_id was removed (field_removed)`,
+		oldPath + `:78:3: error: value east of enum Direction was removed (enum_value_removed)`,
+	})
+
+	assert.Equal(t, len(breaking.Compare(load(t, newPath), load(t, newPath), nil)), 0)
+}
+
+// TestCompareDump checks that a schema read back from a dump compares like the compiled schema.
+func TestCompareDump(t *testing.T) {
+	src, err := json.Marshal(load(t, oldPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var old ir.Schema
+	if err := json.Unmarshal(src, &old); err != nil {
+		t.Fatal(err)
+	}
+	new := load(t, newPath)
+	assert.DeepEqual(t, messages(breaking.Compare(&old, new, nil)), messages(breaking.Compare(load(t, oldPath), new, nil)))
+}
+
+func TestCompareConfig(t *testing.T) {
+	config, err := breaking.ParseConfig(map[string]string{
+		"field_removed":      "error",
+		"model_removed":      "off",
+		"field_type_changed": "warning",
+	})
+	assert.NilError(t, err)
+	counts := map[firemodel.Severity]int{}
+	for _, d := range breaking.Compare(load(t, oldPath), load(t, newPath), config) {
+		counts[d.Severity]++
+	}
+	assert.DeepEqual(t, counts, map[firemodel.Severity]int{
+		firemodel.SeverityError:   15,
+		firemodel.SeverityWarning: 6,
+	})
+
+	_, err = breaking.ParseConfig(map[string]string{"field_renamed": "error"})
+	assert.Error(t, err, "firemodel: unknown breaking change rule field_renamed")
+	_, err = breaking.ParseConfig(map[string]string{"field_removed": "fatal"})
+	assert.Error(t, err, `firemodel: invalid severity "fatal" for rule field_removed; use error, warning or off`)
+}
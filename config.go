@@ -1,9 +1,12 @@
 package firemodel
 
 import (
+	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
+	"github.com/iancoleman/strcase"
 	"github.com/pkg/errors"
 )
 
@@ -11,7 +14,159 @@ type Schema struct {
 	Models  []*SchemaModel
 	Enums   []*SchemaEnum
 	Structs []*SchemaStruct
+	Unions  []*SchemaUnion
+	Aliases []*SchemaAlias
 	Options SchemaOptions
+	// Warnings are the problems found in the schema that don't prevent it from being compiled.
+	Warnings Diagnostics
+	// Files are the paths of the schema files the schema was read from, including imported files.
+	Files []string
+}
+
+// PackageNames returns the names of the packages with declarations in the schema, in the order
+// they are first declared. The default package is named "".
+func (s *Schema) PackageNames() (out []string) {
+	seen := map[string]bool{}
+	add := func(pkg string) {
+		if !seen[pkg] {
+			seen[pkg] = true
+			out = append(out, pkg)
+		}
+	}
+	for _, enum := range s.Enums {
+		add(enum.Package)
+	}
+	for _, structType := range s.Structs {
+		add(structType.Package)
+	}
+	for _, union := range s.Unions {
+		add(union.Package)
+	}
+	for _, alias := range s.Aliases {
+		add(alias.Package)
+	}
+	for _, model := range s.Models {
+		add(model.Package)
+	}
+	return
+}
+
+// InPackage returns a copy of the schema that only includes the declarations in package pkg.
+func (s *Schema) InPackage(pkg string) *Schema {
+	out := &Schema{Options: s.Options}
+	for _, enum := range s.Enums {
+		if enum.Package == pkg {
+			out.Enums = append(out.Enums, enum)
+		}
+	}
+	for _, structType := range s.Structs {
+		if structType.Package == pkg {
+			out.Structs = append(out.Structs, structType)
+		}
+	}
+	for _, union := range s.Unions {
+		if union.Package == pkg {
+			out.Unions = append(out.Unions, union)
+		}
+	}
+	for _, alias := range s.Aliases {
+		if alias.Package == pkg {
+			out.Aliases = append(out.Aliases, alias)
+		}
+	}
+	for _, model := range s.Models {
+		if model.Package == pkg {
+			out.Models = append(out.Models, model)
+		}
+	}
+	return out
+}
+
+// Struct returns the struct named name in package pkg, or nil if there is none.
+func (s *Schema) Struct(pkg string, name string) *SchemaStruct {
+	for _, structType := range s.Structs {
+		if structType.Package == pkg && structType.Name == name {
+			return structType
+		}
+	}
+	return nil
+}
+
+// Union returns the union named name in package pkg, or nil if there is none.
+func (s *Schema) Union(pkg string, name string) *SchemaUnion {
+	for _, union := range s.Unions {
+		if union.Package == pkg && union.Name == name {
+			return union
+		}
+	}
+	return nil
+}
+
+// Model returns the model named name in package pkg, or nil if there is none.
+func (s *Schema) Model(pkg string, name string) *SchemaModel {
+	for _, model := range s.Models {
+		if model.Package == pkg && model.Name == name {
+			return model
+		}
+	}
+	return nil
+}
+
+// Enum returns the enum named name in package pkg, or nil if there is none.
+func (s *Schema) Enum(pkg string, name string) *SchemaEnum {
+	for _, enum := range s.Enums {
+		if enum.Package == pkg && enum.Name == name {
+			return enum
+		}
+	}
+	return nil
+}
+
+// Alias returns the alias named name in package pkg, or nil if there is none.
+func (s *Schema) Alias(pkg string, name string) *SchemaAlias {
+	for _, alias := range s.Aliases {
+		if alias.Package == pkg && alias.Name == name {
+			return alias
+		}
+	}
+	return nil
+}
+
+// HasValidation returns true if any of fields has validation constraints, or is a struct (or an
+// array of structs) with fields that have validation constraints.
+func (s *Schema) HasValidation(fields []*SchemaField) bool {
+	return s.hasValidation(fields, map[*SchemaStruct]bool{})
+}
+
+func (s *Schema) hasValidation(fields []*SchemaField, seen map[*SchemaStruct]bool) bool {
+	for _, field := range fields {
+		if len(field.Constraints) > 0 {
+			return true
+		}
+		structType := s.NestedStruct(field.Type)
+		if structType == nil || seen[structType] {
+			continue
+		}
+		seen[structType] = true
+		if s.hasValidation(structType.Fields, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// NestedStruct returns the struct that a field of type fieldType stores, directly or as the items
+// of an array, or nil if it stores no struct.
+func (s *Schema) NestedStruct(fieldType SchemaFieldType) *SchemaStruct {
+	switch fieldType := fieldType.(type) {
+	case *Struct:
+		return s.Struct(fieldType.T.Package, fieldType.T.Name)
+	case *Array:
+		if structType, ok := fieldType.T.(*Struct); ok {
+			return s.Struct(structType.T.Package, structType.T.Name)
+		}
+	}
+	return nil
 }
 
 type Config struct {
@@ -20,17 +175,63 @@ type Config struct {
 }
 
 type SchemaModel struct {
-	Name        string
-	Comment     string
+	Name    string
+	Package string
+	Comment string
+	// Extends are the structs that the model extends, e.g. `model Invoice extends Owned {}`.
+	Extends []*SchemaStruct
+	// Fields are the fields of the model, starting with the fields inherited from Extends.
 	Fields      []*SchemaField
 	Collections []*SchemaNestedCollection
-	Options     SchemaModelOptions
+	// Reserved are the names of removed fields, which no field may use or be stored as.
+	Reserved []string
+	Options  SchemaModelOptions
+	Pos      Position
 }
 
 type SchemaStruct struct {
 	Name    string
+	Package string
 	Comment string
-	Fields  []*SchemaField
+	// Extends are the structs that the struct extends.
+	Extends []*SchemaStruct
+	// Fields are the fields of the struct, starting with the fields inherited from Extends.
+	Fields []*SchemaField
+	// Reserved are the names of removed fields, which no field may use or be stored as.
+	Reserved []string
+	Pos      Position
+}
+
+// SchemaUnion is a value that holds exactly one of its members. It is stored as a map with the
+// name of the member under the discriminator key, and the member under its own name, e.g.
+// `{type: "card", card: {...}}`.
+type SchemaUnion struct {
+	Name    string
+	Package string
+	Comment string
+	// Discriminator is the key that the name of the member is stored under, set with the
+	// firestore.discriminator option. It defaults to "type".
+	Discriminator string
+	// Members are the members of the union. The type of every member is a *Struct, and its
+	// WireName is both the key it is stored under and the value of the discriminator.
+	Members []*SchemaField
+	Options SchemaModelOptions
+	Pos     Position
+}
+
+// SchemaAlias is a name for a primitive type, e.g. `type Email = string;`. Fields declared with an
+// alias have the aliased type, and the constraints of the alias.
+type SchemaAlias struct {
+	Name    string
+	Package string
+	Comment string
+	// Type is the aliased type. Aliases of aliases are resolved to the primitive type.
+	Type SchemaFieldType
+	// Constraints are the validation constraints of the alias, including the constraints of the
+	// alias it aliases.
+	Constraints []*SchemaConstraint
+	Options     SchemaFieldOptions
+	Pos         Position
 }
 
 type SchemaOptions map[string]map[string]string
@@ -51,6 +252,17 @@ func (options SchemaModelOptions) Get(key string) map[string]string {
 	return map[string]string{}
 }
 
+// SchemaFieldOptions are the options set on a field, keyed by namespace. Options set without a
+// namespace (e.g. `[deprecated = true]`) are stored under "".
+type SchemaFieldOptions SchemaOptions
+
+func (options SchemaFieldOptions) Get(key string) map[string]string {
+	if res, ok := options[key]; ok {
+		return res
+	}
+	return map[string]string{}
+}
+
 var (
 	firestorePathVariablePattern = regexp.MustCompile("^{([a-zA-Z0-9_-]+)}$")
 	firestorePathConstantPattern = regexp.MustCompile("^([a-zA-Z0-9_-]+)$")
@@ -117,14 +329,178 @@ func (options SchemaModelOptions) GetAutoTimestamp() bool {
 
 type SchemaEnum struct {
 	Name    string
+	Package string
 	Comment string
+	Backing EnumBacking
 	Values  []*SchemaEnumValue
+	// Reserved are the names (strings) and stored values (strings or int64s) of removed values,
+	// which no value may use or be stored as.
+	Reserved []interface{}
+	Pos      Position
+}
+
+// IsIntegerBacked returns true if the values of the enum are stored as integers.
+func (e *SchemaEnum) IsIntegerBacked() bool {
+	return e.Backing == EnumBackingInteger
+}
+
+// EnumBacking is the type that the values of an enum are stored as.
+type EnumBacking int
+
+const (
+	EnumBackingString EnumBacking = iota
+	// EnumBackingInteger enums are declared as `enum Priority : integer { ... }`.
+	EnumBackingInteger
+)
+
+func (backing EnumBacking) String() string {
+	if backing == EnumBackingInteger {
+		return "integer"
+	}
+	return "string"
 }
 
 type SchemaField struct {
-	Name    string
-	Comment string
-	Type    SchemaFieldType
+	Name string
+	// WireName is the key the field is stored under in Firestore.
+	WireName string
+	Comment  string
+	Type     SchemaFieldType
+	Presence FieldPresence
+	// Nullable is true if the field may be stored as null, e.g. `string? name;`.
+	Nullable bool
+	// Default is the default value of the field, or nil if it has none. It is a bool, int64,
+	// float64 or string, or a *SchemaEnumValue naming a value of an enum field.
+	Default interface{}
+	// Constraints are the validation constraints of the field, in the order they are declared.
+	Constraints []*SchemaConstraint
+	Options     SchemaFieldOptions
+	// Deprecated is true if the field is marked with the deprecated option, e.g.
+	// `string name [deprecated = true];`.
+	Deprecated bool
+	// Alias is the alias the field was declared with, or nil. Type is the type it aliases, and
+	// Constraints include its constraints.
+	Alias *SchemaAlias
+	// Origin is the struct in the extends clause of the enclosing model or struct that the field
+	// was inherited from, or nil if the field was declared there.
+	Origin *SchemaStruct
+	Pos    Position
+}
+
+// IsRequired returns true if the field was declared required.
+func (f *SchemaField) IsRequired() bool {
+	return f.Presence == PresenceRequired
+}
+
+// IsOptional returns true if the field was declared optional.
+func (f *SchemaField) IsOptional() bool {
+	return f.Presence == PresenceOptional
+}
+
+// ConstraintKind is the kind of a validation constraint. Constraints are set with the field option
+// of the same name, e.g. `integer age [min = 0];`.
+type ConstraintKind string
+
+const (
+	// ConstraintMin and ConstraintMax bound integer and double fields.
+	ConstraintMin ConstraintKind = "min"
+	ConstraintMax ConstraintKind = "max"
+	// ConstraintMinLength and ConstraintMaxLength bound the number of characters (Unicode code
+	// points) of string fields.
+	ConstraintMinLength ConstraintKind = "min_length"
+	ConstraintMaxLength ConstraintKind = "max_length"
+	// ConstraintPattern requires string fields to match a regular expression.
+	ConstraintPattern ConstraintKind = "pattern"
+	// ConstraintNonEmpty requires string, array and map fields to be non-empty.
+	ConstraintNonEmpty ConstraintKind = "non_empty"
+	// ConstraintMinItems and ConstraintMaxItems bound the number of items of array fields.
+	ConstraintMinItems ConstraintKind = "min_items"
+	ConstraintMaxItems ConstraintKind = "max_items"
+)
+
+// SchemaConstraint is a validation constraint on the value of a field.
+type SchemaConstraint struct {
+	Kind ConstraintKind
+	// Limit is the bound of min, max, length and items constraints.
+	Limit float64
+	// Pattern is the regular expression of pattern constraints.
+	Pattern string
+}
+
+// FormatLimit formats the limit of the constraint as a number literal.
+func (c *SchemaConstraint) FormatLimit() string {
+	return strconv.FormatFloat(c.Limit, 'f', -1, 64)
+}
+
+// Message describes what the constraint requires of a value, e.g. "must be >= 0". Every modeler
+// reports violations with the name of the field followed by this message.
+func (c *SchemaConstraint) Message() string {
+	switch c.Kind {
+	case ConstraintMin:
+		return fmt.Sprintf("must be >= %s", c.FormatLimit())
+	case ConstraintMax:
+		return fmt.Sprintf("must be <= %s", c.FormatLimit())
+	case ConstraintMinLength:
+		return fmt.Sprintf("length must be >= %s", c.FormatLimit())
+	case ConstraintMaxLength:
+		return fmt.Sprintf("length must be <= %s", c.FormatLimit())
+	case ConstraintPattern:
+		return fmt.Sprintf("must match %s", c.Pattern)
+	case ConstraintNonEmpty:
+		return "must not be empty"
+	case ConstraintMinItems:
+		return fmt.Sprintf("size must be >= %s", c.FormatLimit())
+	case ConstraintMaxItems:
+		return fmt.Sprintf("size must be <= %s", c.FormatLimit())
+	default:
+		return fmt.Sprintf("must satisfy %s", c.Kind)
+	}
+}
+
+// FieldPresence describes whether a field must be present in every document.
+type FieldPresence int
+
+const (
+	// PresenceDefault is the presence of a field declared without a modifier. Modelers generate
+	// these fields as they always have.
+	PresenceDefault FieldPresence = iota
+	// PresenceRequired fields are present in every document.
+	PresenceRequired
+	// PresenceOptional fields may be missing from a document.
+	PresenceOptional
+)
+
+// FieldNaming is a policy that derives the Firestore key of a field from the name it is declared
+// with. It is set for a whole schema or a single model with the firestore.naming option.
+type FieldNaming string
+
+const (
+	FieldNamingLowerCamel FieldNaming = "lower_camel"
+	FieldNamingSnake      FieldNaming = "snake"
+	FieldNamingVerbatim   FieldNaming = "verbatim"
+)
+
+var fieldNamings = []FieldNaming{FieldNamingLowerCamel, FieldNamingSnake, FieldNamingVerbatim}
+
+func (naming FieldNaming) IsValid() bool {
+	for _, valid := range fieldNamings {
+		if naming == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply returns the Firestore key of a field declared as name.
+func (naming FieldNaming) Apply(name string) string {
+	switch naming {
+	case FieldNamingSnake:
+		return strcase.ToSnake(name)
+	case FieldNamingVerbatim:
+		return name
+	default:
+		return strcase.ToLowerCamel(strcase.ToSnake(name))
+	}
 }
 
 type SchemaFieldType interface {
@@ -132,8 +508,15 @@ type SchemaFieldType interface {
 }
 
 type SchemaEnumValue struct {
-	Name    string
-	Comment string
+	Name string
+	// WireValue is the value stored in Firestore: a string, or an int64 for integer enums.
+	WireValue interface{}
+	// Aliases are other stored values that decode to this value, e.g. the values it was stored as
+	// before being renamed.
+	Aliases    []interface{}
+	Deprecated bool
+	Comment    string
+	Pos        Position
 }
 
 type Boolean struct{}
@@ -145,9 +528,15 @@ type String struct{}
 type Bytes struct{}
 type Reference struct{ T *SchemaModel }
 type Array struct{ T SchemaFieldType }
-type Map struct{ T SchemaFieldType }
+type Map struct {
+	// K is the type of the keys of maps declared as map<K, V>, which is an enum stored as strings.
+	// Keys of other maps are strings, and K is nil.
+	K SchemaFieldType
+	T SchemaFieldType
+}
 type Struct struct{ T *SchemaStruct }
 type Enum struct{ T *SchemaEnum }
+type Union struct{ T *SchemaUnion }
 type URL struct{}
 type File struct{}
 
@@ -163,6 +552,7 @@ func (t *Array) isSchemaTypeName()     {}
 func (t *Map) isSchemaTypeName()       {}
 func (t *Struct) isSchemaTypeName()    {}
 func (t *Enum) isSchemaTypeName()      {}
+func (t *Union) isSchemaTypeName()     {}
 func (t *URL) isSchemaTypeName()       {}
 func (t *File) isSchemaTypeName()      {}
 
@@ -170,4 +560,5 @@ type SchemaNestedCollection struct {
 	Name    string
 	Comment string
 	Type    *SchemaModel
+	Pos     Position
 }
@@ -0,0 +1,98 @@
+package firemodel
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/participle/lexer"
+)
+
+// Position is a location in a firemodel schema source file.
+type Position struct {
+	Filename string
+	Line     int
+	Column   int
+}
+
+func (p Position) String() string {
+	filename := p.Filename
+	if filename == "" {
+		filename = "<source>"
+	}
+	return fmt.Sprintf("%s:%d:%d", filename, p.Line, p.Column)
+}
+
+func positionOf(pos lexer.Position) Position {
+	return Position{
+		Filename: pos.Filename,
+		Line:     pos.Line,
+		Column:   pos.Column,
+	}
+}
+
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+}
+
+// Diagnostic is a single problem found in a schema, along with where it was found.
+type Diagnostic struct {
+	Pos      Position
+	Severity Severity
+	Message  string
+}
+
+func (d *Diagnostic) Error() string {
+	return fmt.Sprintf("%s: %s: %s", d.Pos, d.Severity, d.Message)
+}
+
+// Diagnostics is the list of problems found while compiling a schema.
+//
+// ParseSchema returns Diagnostics as its error when the schema has at least one error, so that
+// every problem can be reported in a single pass.
+type Diagnostics []*Diagnostic
+
+func (ds Diagnostics) Error() string {
+	lines := make([]string, len(ds))
+	for idx, d := range ds {
+		lines[idx] = d.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// HasErrors returns true if any of the diagnostics is an error, rather than a warning.
+func (ds Diagnostics) HasErrors() bool {
+	for _, d := range ds {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+func (ds Diagnostics) sort() {
+	sort.SliceStable(ds, func(i, j int) bool {
+		a, b := ds[i].Pos, ds[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+}
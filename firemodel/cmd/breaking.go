@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/visor-tax/firemodel"
+	"github.com/visor-tax/firemodel/breaking"
+	"github.com/visor-tax/firemodel/ir"
+)
+
+var breakingReq struct {
+	against []string
+	rules   map[string]string
+}
+
+func init() {
+	breakingCmd.PersistentFlags().StringSliceVar(&req.schemas, "schema", []string{"schema.firemodel"}, "Path to firemodel schema.")
+	breakingCmd.Flags().StringSliceVar(&breakingReq.against, "against", nil, "Path to the old version of the schema: schema files, or a .json file written by firemodel dump.")
+	breakingCmd.Flags().StringToStringVar(&breakingReq.rules, "rule", nil, "Severities of rules, as rule=error|warning|off pairs. Overrides the project file.")
+}
+
+var breakingCmd = &cobra.Command{
+	Use:   "breaking",
+	Short: "Check a schema for changes that break clients of an older version of it.",
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if len(breakingReq.against) == 0 {
+			return errors.New("--against is required")
+		}
+		return loadProject(cmd)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		severities := map[string]string{}
+		if req.project != nil {
+			for rule, severity := range req.project.Breaking {
+				severities[rule] = severity
+			}
+		}
+		for rule, severity := range breakingReq.rules {
+			severities[rule] = severity
+		}
+		config, err := breaking.ParseConfig(severities)
+		if err != nil {
+			return err
+		}
+
+		old, err := loadAgainst(breakingReq.against)
+		if err != nil {
+			return err
+		}
+		schema, err := parseSchemas()
+		if err != nil {
+			return err
+		}
+
+		diagnostics := breaking.Compare(old, ir.FromSchema(schema), config)
+		if diagnostics.HasErrors() {
+			return diagnostics
+		}
+		if len(diagnostics) > 0 {
+			fmt.Fprintln(os.Stderr, diagnostics)
+		}
+		return nil
+	},
+}
+
+// loadAgainst loads the old version of the schema from a dump, if paths is a single .json file,
+// or from schema files otherwise.
+func loadAgainst(paths []string) (*ir.Schema, error) {
+	if len(paths) == 1 && filepath.Ext(paths[0]) == ".json" {
+		f, err := os.Open(paths[0])
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		schema := &ir.Schema{}
+		if err := json.NewDecoder(f).Decode(schema); err != nil {
+			return nil, errors.Wrapf(err, "invalid schema dump %s", paths[0])
+		}
+		if schema.Version != ir.Version {
+			return nil, errors.Errorf("schema dump %s has version %d; this version of firemodel reads version %d", paths[0], schema.Version, ir.Version)
+		}
+		return schema, nil
+	}
+
+	files, err := schemaPaths(paths)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := firemodel.ParseSchemaFiles(files...)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't compile the old schema")
+	}
+	if req.project != nil {
+		req.project.ApplyOptions(schema)
+	}
+	return ir.FromSchema(schema), nil
+}
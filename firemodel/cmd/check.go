@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/visor-tax/firemodel"
+	"github.com/visor-tax/firemodel/internal/diff"
+	"github.com/visor-tax/firemodel/internal/memwriter"
+	"github.com/visor-tax/firemodel/internal/tempwriter"
+)
+
+// checkGenerated runs the modelers of config in memory, and prints a unified diff of every file in
+// their output directories that compiling would change. It returns an error if any file would
+// change. Nothing is written to the output directories.
+func checkGenerated(schema *firemodel.Schema, config *firemodel.Config) error {
+	var writers []*memwriter.MemWriter
+	config.SourceCoderProvider = func(prefix string) firemodel.SourceCoder {
+		w := memwriter.New(prefix)
+		writers = append(writers, w)
+		return w
+	}
+	if err := firemodel.Run(schema, config); err != nil {
+		return err
+	}
+
+	stale := 0
+	for _, w := range writers {
+		files := w.Files()
+		var filenames []string
+		for filename := range files {
+			filenames = append(filenames, filename)
+		}
+		sort.Strings(filenames)
+		for _, filename := range filenames {
+			changed, err := diffFile(filepath.Join(w.Prefix(), filename), files[filename])
+			if err != nil {
+				return err
+			}
+			if changed {
+				stale++
+			}
+		}
+
+		// Compiling removes the files that are no longer generated.
+		removed, err := tempwriter.Stale(w.Prefix(), filenames, compileReq.wipe)
+		if err != nil {
+			return err
+		}
+		for _, path := range removed {
+			changed, err := diffFile(path, nil)
+			if err != nil {
+				return err
+			}
+			if changed {
+				stale++
+			}
+		}
+	}
+	if stale > 0 {
+		return errors.Errorf("%d files in the output directories are out of date; run firemodel compile to update them", stale)
+	}
+	return nil
+}
+
+// diffFile prints the diff between the file on disk and its generated contents, which are nil if
+// the file is no longer generated. It returns true if they differ.
+func diffFile(filename string, generated []byte) (bool, error) {
+	fromName, toName := filename, filename
+	existing, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		fromName = "/dev/null"
+	} else if err != nil {
+		return false, err
+	}
+	if generated == nil {
+		toName = "/dev/null"
+	}
+	if fromName == toName && string(existing) == string(generated) {
+		return false, nil
+	}
+	if d := diff.Unified(fromName, toName, string(existing), string(generated)); d != "" {
+		fmt.Print(d)
+	} else {
+		// The file is created or removed, but empty.
+		fmt.Printf("--- %s\n+++ %s\n", fromName, toName)
+	}
+	return true, nil
+}
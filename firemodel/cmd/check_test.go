@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/visor-tax/firemodel"
+	"github.com/visor-tax/firemodel/internal/tempwriter"
+	"gotest.tools/assert"
+)
+
+func TestCheckGenerated(t *testing.T) {
+	dir, err := ioutil.TempDir("", "check")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	out := filepath.Join(dir, "out")
+	schemaPath := filepath.Join(dir, "schema.firemodel")
+
+	parse := func(src string) *firemodel.Schema {
+		assert.NilError(t, ioutil.WriteFile(schemaPath, []byte(src), 0644))
+		schema, err := firemodel.ParseSchemaFiles(schemaPath)
+		assert.NilError(t, err)
+		return schema
+	}
+	config := func(writer func(prefix string) firemodel.SourceCoder) *firemodel.Config {
+		return &firemodel.Config{
+			Languages:           []firemodel.Language{{Language: "go", Output: out}},
+			SourceCoderProvider: writer,
+		}
+	}
+	compile := func(schema *firemodel.Schema) {
+		assert.NilError(t, firemodel.Run(schema, config(func(prefix string) firemodel.SourceCoder {
+			return tempwriter.New(prefix, false)
+		})))
+	}
+
+	schema := parse("option go.package = \"models\";\nmodel User { string name; }\nmodel Team { string name; }\n")
+	assert.ErrorContains(t, checkGenerated(schema, config(nil)), "out of date")
+	compile(schema)
+	assert.NilError(t, checkGenerated(schema, config(nil)))
+
+	// A generated file that was edited is out of date.
+	path := filepath.Join(out, "user.firemodel.go")
+	contents, err := ioutil.ReadFile(path)
+	assert.NilError(t, err)
+	assert.NilError(t, ioutil.WriteFile(path, append(contents, "// edited\n"...), 0644))
+	assert.ErrorContains(t, checkGenerated(schema, config(nil)), "1 files in the output directories are out of date")
+	compile(schema)
+	assert.NilError(t, checkGenerated(schema, config(nil)))
+
+	// So is a file that is no longer generated, which compiling would remove.
+	schema = parse("option go.package = \"models\";\nmodel User { string name; }\n")
+	assert.ErrorContains(t, checkGenerated(schema, config(nil)), "1 files in the output directories are out of date")
+	_, err = os.Stat(filepath.Join(out, "team.firemodel.go"))
+	assert.NilError(t, err, "checking removed a file")
+}
@@ -2,29 +2,42 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/visor-tax/firemodel"
 	"github.com/visor-tax/firemodel/internal/tempwriter"
-	"io"
-	"os"
-	"path/filepath"
 )
 
 var compileReq struct {
 	wipe        bool
+	check       bool
+	watch       bool
 	langOutDirs map[string]*string
+	// pluginParams are the --<lang>_opt parameters of the languages generated by plugins.
+	pluginParams map[string]*map[string]string
 }
 
 func init() {
 	compileCmd.PersistentFlags().StringSliceVar(&req.schemas, "schema", []string{"schema.firemodel"}, "Path to firemodel schema.")
-	compileCmd.PersistentFlags().BoolVarP(&compileReq.wipe, "wipe", "f", false, "Confirms it is ok to rm -rf the output directories. (This is generally something you want, but defaults off for safety.)")
+	compileCmd.PersistentFlags().BoolVarP(&compileReq.wipe, "wipe", "f", false, "Confirms it is ok to remove every file in the output directories that isn't generated. (Files generated by earlier runs are always removed when they are no longer generated.)")
+
+	compileCmd.PersistentFlags().BoolVar(&compileReq.check, "check", false, "Print a diff of the changes compiling would make to the output directories, and fail if there are any, without changing them.")
+	compileCmd.PersistentFlags().BoolVar(&compileReq.watch, "watch", false, "Compile again whenever the schema files change, until interrupted.")
 
 	compileReq.langOutDirs = make(map[string]*string)
 	for _, modeler := range firemodel.AllModelers() {
 		compileReq.langOutDirs[modeler] = new(string)
 		compileCmd.PersistentFlags().StringVar(compileReq.langOutDirs[modeler], modeler+"_out", "", fmt.Sprintf("%s output directory", modeler))
 	}
+	compileReq.pluginParams = make(map[string]*map[string]string)
+	for _, language := range pluginLanguages(os.Args[1:]) {
+		compileReq.langOutDirs[language] = new(string)
+		compileCmd.PersistentFlags().StringVar(compileReq.langOutDirs[language], language+"_out", "", fmt.Sprintf("%s output directory (generated by %s%s)", language, firemodel.PluginPrefix, language))
+		compileReq.pluginParams[language] = new(map[string]string)
+		compileCmd.PersistentFlags().StringToStringVar(compileReq.pluginParams[language], language+"_opt", nil, fmt.Sprintf("Parameters of the %s plugin, as key=value pairs.", language))
+	}
 }
 
 var compileCmd = &cobra.Command{
@@ -32,51 +45,32 @@ var compileCmd = &cobra.Command{
 	Aliases: []string{"c"},
 	Short:   "Type-safe, cross-platform models for Firestore",
 	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := loadProject(cmd); err != nil {
+			return err
+		}
 		for k := range compileReq.langOutDirs {
 			if !cmd.Flag(k + "_out").Changed {
 				delete(compileReq.langOutDirs, k)
 			}
 		}
+		// The languages of the project are generated unless languages are requested with flags.
+		if len(compileReq.langOutDirs) == 0 && req.project != nil {
+			for _, name := range req.project.LanguageNames() {
+				compileReq.langOutDirs[name] = &req.project.Languages[name].Output
+			}
+		}
+		if req.project != nil && !cmd.Flag("wipe").Changed {
+			compileReq.wipe = req.project.Wipe
+		}
 		if len(compileReq.langOutDirs) == 0 {
 			return errors.New("no languages requested")
 		}
-		return nil
+		if compileReq.check && compileReq.watch {
+			return errors.New("--check and --watch can't be combined")
+		}
+		return registerPlugins()
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		var rs []io.Reader
-		for _, schema := range req.schemas {
-			paths, err := filepath.Glob(schema)
-			if err != nil {
-				return err
-			}
-			if paths == nil {
-				return errors.Errorf("No files match glob pattern %+s", schema)
-			}
-			for _, path := range paths {
-				info, err := os.Stat(path)
-				if err != nil {
-					return err
-				} else if info.IsDir() {
-					return errors.Errorf("%+s is a directory", info.Name())
-				} else if rr, err := os.Open(path); err != nil {
-					return err
-				} else {
-					rs = append(rs, rr)
-				}
-			}
-		}
-		// Sanity check.
-		if len(rs) == 0 {
-			panic(errors.New("No readable schema files provided."))
-		}
-
-		r := io.MultiReader(rs...)
-
-		schema, err := firemodel.ParseSchema(r)
-		if err != nil {
-			panic(err)
-		}
-
 		config := &firemodel.Config{
 			SourceCoderProvider: func(prefix string) firemodel.SourceCoder {
 				return tempwriter.New(prefix, compileReq.wipe)
@@ -93,10 +87,16 @@ var compileCmd = &cobra.Command{
 			})
 		}
 
-		if err := firemodel.Run(schema, config); err != nil {
-			panic(err)
+		if compileReq.watch {
+			return watch(config)
 		}
-
-		return nil
+		schema, err := parseSchemas()
+		if err != nil {
+			return err
+		}
+		if compileReq.check {
+			return checkGenerated(schema, config)
+		}
+		return firemodel.Run(schema, config)
 	},
 }
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/visor-tax/firemodel/ir"
+)
+
+var dumpReq struct {
+	output string
+}
+
+func init() {
+	dumpCmd.PersistentFlags().StringSliceVar(&req.schemas, "schema", []string{"schema.firemodel"}, "Path to firemodel schema.")
+	dumpCmd.Flags().StringVarP(&dumpReq.output, "output", "o", "", "Write the result to a file instead of stdout.")
+}
+
+var dumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Print the compiled schema as JSON, for tools that consume schemas.",
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return loadProject(cmd)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schema, err := parseSchemas()
+		if err != nil {
+			return err
+		}
+		out := os.Stdout
+		if dumpReq.output != "" {
+			if out, err = os.Create(dumpReq.output); err != nil {
+				return err
+			}
+			defer out.Close()
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(ir.FromSchema(schema))
+	},
+}
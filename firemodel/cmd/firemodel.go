@@ -3,8 +3,12 @@ package cmd
 import (
 	// Modeler registrations:
 	"fmt"
+	"os"
+	"path/filepath"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"github.com/visor-tax/firemodel"
 	_ "github.com/visor-tax/firemodel/langs/go"
 	_ "github.com/visor-tax/firemodel/langs/ios"
 	_ "github.com/visor-tax/firemodel/langs/ts"
@@ -14,6 +18,9 @@ import (
 var (
 	req struct {
 		schemas []string
+		config  string
+		// project is the project file, or nil if there is none.
+		project *firemodel.Project
 	}
 )
 
@@ -21,15 +28,90 @@ var rootCmd = &cobra.Command{
 	Use:     "firemodel",
 	Short:   "Type-safe, cross-platform models for Firestore",
 	Version: version.Version,
+	// Errors (including schema diagnostics) are printed by Execute.
+	SilenceErrors: true,
+	SilenceUsage:  true,
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&req.config, "config", "", fmt.Sprintf("Path to the project file. Defaults to the closest %s in the working directory or its parents.", firemodel.ProjectFileName))
+
 	rootCmd.AddCommand(showCmd)
 	rootCmd.AddCommand(compileCmd)
+	rootCmd.AddCommand(lintCmd)
+	rootCmd.AddCommand(fmtCmd)
+	rootCmd.AddCommand(dumpCmd)
+	rootCmd.AddCommand(breakingCmd)
+}
+
+// loadProject loads the project file, and uses its schemas unless --schema is set.
+func loadProject(cmd *cobra.Command) error {
+	var err error
+	if req.config != "" {
+		req.project, err = firemodel.LoadProject(req.config)
+	} else {
+		req.project, err = firemodel.FindProject(".")
+	}
+	if err != nil {
+		return err
+	}
+	if req.project != nil && len(req.project.Schemas) > 0 && !cmd.Flag("schema").Changed {
+		req.schemas = req.project.Schemas
+	}
+	return nil
+}
+
+// parseSchemas parses the schema files matching the --schema globs, and applies the options of
+// the project file. Warnings are printed to stderr; errors are returned as firemodel.Diagnostics.
+func parseSchemas() (*firemodel.Schema, error) {
+	paths, err := schemaPaths(req.schemas)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := firemodel.ParseSchemaFiles(paths...)
+	if err != nil {
+		return nil, err
+	}
+	if len(schema.Warnings) > 0 {
+		fmt.Fprintln(os.Stderr, schema.Warnings)
+	}
+	if req.project != nil {
+		req.project.ApplyOptions(schema)
+	}
+	return schema, nil
+}
+
+// schemaPaths returns the paths of the schema files matching globs.
+func schemaPaths(globs []string) ([]string, error) {
+	var paths []string
+	for _, schema := range globs {
+		matches, err := filepath.Glob(schema)
+		if err != nil {
+			return nil, err
+		}
+		if matches == nil {
+			return nil, errors.Errorf("No files match glob pattern %+s", schema)
+		}
+		for _, path := range matches {
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, err
+			} else if info.IsDir() {
+				return nil, errors.Errorf("%+s is a directory", info.Name())
+			}
+			paths = append(paths, path)
+		}
+	}
+	// Sanity check.
+	if len(paths) == 0 {
+		return nil, errors.New("No readable schema files provided.")
+	}
+	return paths, nil
 }
 
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 }
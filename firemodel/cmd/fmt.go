@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/visor-tax/firemodel"
+	"github.com/visor-tax/firemodel/internal/diff"
+)
+
+var fmtReq struct {
+	write bool
+	diff  bool
+}
+
+func init() {
+	fmtCmd.Flags().BoolVarP(&fmtReq.write, "write", "w", false, "Write the result to the source files instead of stdout.")
+	fmtCmd.Flags().BoolVarP(&fmtReq.diff, "diff", "d", false, "Print diffs to the source files instead of the result.")
+}
+
+var fmtCmd = &cobra.Command{
+	Use:   "fmt [files...]",
+	Short: "Format schema files, or stdin if no files are given.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			if fmtReq.write {
+				return errors.New("can't write the result to stdin")
+			}
+			src, err := ioutil.ReadAll(os.Stdin)
+			if err != nil {
+				return err
+			}
+			return formatFile("<stdin>", src, 0)
+		}
+		for _, path := range args {
+			info, err := os.Stat(path)
+			if err != nil {
+				return err
+			}
+			src, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if err := formatFile(path, src, info.Mode()); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// namedReader is a reader with a file name, which positions in diagnostics refer to.
+type namedReader struct {
+	*bytes.Reader
+	name string
+}
+
+func (r *namedReader) Name() string {
+	return r.name
+}
+
+// formatFile formats the schema src, read from the file named filename, and writes or prints the
+// result depending on the flags.
+func formatFile(filename string, src []byte, mode os.FileMode) error {
+	formatted, err := firemodel.FormatSchema(&namedReader{bytes.NewReader(src), filename})
+	if err != nil {
+		return err
+	}
+	if fmtReq.diff {
+		fmt.Print(diff.Unified(filename, filename, string(src), string(formatted)))
+	}
+	if fmtReq.write && !bytes.Equal(src, formatted) {
+		return ioutil.WriteFile(filename, formatted, mode)
+	}
+	if !fmtReq.write && !fmtReq.diff {
+		os.Stdout.Write(formatted)
+	}
+	return nil
+}
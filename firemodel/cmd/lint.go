@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	lintCmd.PersistentFlags().StringSliceVar(&req.schemas, "schema", []string{"schema.firemodel"}, "Path to firemodel schema.")
+}
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check a schema for errors and warnings without generating code.",
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return loadProject(cmd)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, err := parseSchemas()
+		return err
+	},
+}
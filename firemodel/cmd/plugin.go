@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/visor-tax/firemodel"
+	"github.com/visor-tax/firemodel/plugin"
+)
+
+// pluginLanguages returns the languages of the --<lang>_out and --<lang>_opt flags in args that
+// have no registered modeler, which are generated by plugins. Their flags have to be defined
+// before the arguments are parsed.
+func pluginLanguages(args []string) (out []string) {
+	registered := map[string]bool{}
+	for _, language := range firemodel.AllModelers() {
+		registered[language] = true
+	}
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "--") {
+			continue
+		}
+		name := strings.SplitN(strings.TrimPrefix(arg, "--"), "=", 2)[0]
+		for _, suffix := range []string{"_out", "_opt"} {
+			language := strings.TrimSuffix(name, suffix)
+			if language != name && language != "" && !registered[language] {
+				registered[language] = true
+				out = append(out, language)
+			}
+		}
+	}
+	return
+}
+
+// registerPlugins registers the plugin modelers of the requested languages that have no registered
+// modeler.
+func registerPlugins() error {
+	registered := map[string]bool{}
+	for _, language := range firemodel.AllModelers() {
+		registered[language] = true
+	}
+	for language := range compileReq.langOutDirs {
+		if registered[language] {
+			continue
+		}
+		modeler, err := plugin.Lookup(language)
+		if err != nil {
+			return err
+		}
+		if params := compileReq.pluginParams[language]; params != nil {
+			modeler.Parameters = *params
+		}
+		firemodel.RegisterModeler(language, modeler)
+	}
+	return nil
+}
@@ -4,14 +4,24 @@ import (
 	"fmt"
 	"github.com/spf13/cobra"
 	"github.com/visor-tax/firemodel"
+	"github.com/visor-tax/firemodel/plugin"
 )
 
 var showCmd = &cobra.Command{
 	Use:   "show-languages",
 	Short: "Show all available languages.",
 	Run: func(cmd *cobra.Command, args []string) {
+		registered := map[string]bool{}
 		for _, language := range firemodel.AllModelers() {
+			registered[language] = true
 			fmt.Println(language)
 		}
+		// Registered modelers take precedence over plugins.
+		for _, language := range plugin.Installed() {
+			if registered[language] {
+				continue
+			}
+			fmt.Printf("%s (%s%s)\n", language, firemodel.PluginPrefix, language)
+		}
 	},
 }
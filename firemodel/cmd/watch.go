@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/visor-tax/firemodel"
+	"github.com/visor-tax/firemodel/internal/tempwriter"
+)
+
+const (
+	// watchInterval is how often the schema files are checked for changes.
+	watchInterval = 100 * time.Millisecond
+	// watchDebounce is how long the schema files have to stay unchanged before they are compiled,
+	// so that saving several files at once compiles them once.
+	watchDebounce = 300 * time.Millisecond
+)
+
+// fileState is the state of a schema file that is compared to detect changes.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// watch compiles the schema with config whenever a file matching the --schema globs, or a file
+// they import, changes. Errors are printed, and it keeps watching until it is interrupted.
+func watch(config *firemodel.Config) error {
+	// imported are the files read by the last successful compile.
+	var imported []string
+	compile := func() {
+		schema, err := compileChanged(config)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s %v\n", time.Now().Format("15:04:05"), err)
+			return
+		}
+		imported = schema.Files
+	}
+
+	compile()
+	state := watchedFiles(imported)
+	var changed time.Time
+	for range time.Tick(watchInterval) {
+		next := watchedFiles(imported)
+		if !sameFiles(state, next) {
+			state, changed = next, time.Now()
+			continue
+		}
+		if !changed.IsZero() && time.Since(changed) >= watchDebounce {
+			changed = time.Time{}
+			compile()
+			state = watchedFiles(imported)
+		}
+	}
+	return nil
+}
+
+// compileChanged compiles the schema with config, and prints how many files changed.
+func compileChanged(config *firemodel.Config) (*firemodel.Schema, error) {
+	schema, err := parseSchemas()
+	if err != nil {
+		return nil, err
+	}
+	var writers []*tempwriter.TempWriter
+	config.SourceCoderProvider = func(prefix string) firemodel.SourceCoder {
+		w := tempwriter.New(prefix, compileReq.wipe)
+		writers = append(writers, w)
+		return w
+	}
+	if err := firemodel.Run(schema, config); err != nil {
+		return nil, err
+	}
+
+	written, removed := 0, 0
+	for _, w := range writers {
+		written += len(w.Written())
+		removed += len(w.Removed())
+	}
+	fmt.Fprintf(os.Stderr, "%s compiled: %d files written, %d removed\n", time.Now().Format("15:04:05"), written, removed)
+	return schema, nil
+}
+
+// watchedFiles returns the state of the files matching the --schema globs and of imported, keyed
+// by path. Files that don't exist are left out.
+func watchedFiles(imported []string) map[string]fileState {
+	paths := append([]string{}, imported...)
+	for _, schema := range req.schemas {
+		// Invalid globs are reported when the schema is compiled.
+		matches, _ := filepath.Glob(schema)
+		paths = append(paths, matches...)
+	}
+	out := map[string]fileState{}
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			out[path] = fileState{info.ModTime(), info.Size()}
+		}
+	}
+	return out
+}
+
+func sameFiles(a, b map[string]fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, state := range a {
+		if other, ok := b[path]; !ok || other != state {
+			return false
+		}
+	}
+	return true
+}
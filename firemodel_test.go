@@ -20,20 +20,19 @@ import (
 const fixturesRoot = "testfixtures/firemodel"
 
 func TestFiremodelFromSchema(t *testing.T) {
-	file1, err := os.Open("example/firemodel.example.firemodel")
+	// The example schema imports example/firemodel.common.firemodel.
+	schema, err := firemodel.ParseSchemaFiles("example/firemodel.example.firemodel")
 	if err != nil {
-		panic(err)
-	}
-	defer file1.Close()
-	file2, err := os.Open("example/firemodel.common.firemodel")
-	if err != nil {
-		panic(err)
+		t.Fatal(err)
 	}
-	defer file2.Close()
 
-	schema, err := firemodel.ParseSchema(io.MultiReader(file1, file2))
+	runTest(t, schema)
+}
+
+func TestFiremodelFromPackagedSchema(t *testing.T) {
+	schema, err := firemodel.ParseSchemaFiles("example/packages/app.firemodel")
 	if err != nil {
-		panic(err)
+		t.Fatal(err)
 	}
 
 	runTest(t, schema)
@@ -85,8 +84,13 @@ func runTest(t *testing.T, schema *firemodel.Schema) {
 	}
 
 	if !isUpdate() {
-		fixtures, err := filepath.Glob(path.Join(ctx.prefix, "*", "*"))
-		if err != nil {
+		var fixtures []string
+		if err := filepath.Walk(ctx.prefix, func(path string, info os.FileInfo, err error) error {
+			if err == nil && !info.IsDir() {
+				fixtures = append(fixtures, path)
+			}
+			return err
+		}); err != nil {
 			panic(err)
 		}
 		if len(fixtures) != len(ctx.files) {
@@ -139,6 +143,9 @@ func isUpdate() bool {
 
 func (ctx *testSourceCoder) NewFile(filename string) (io.WriteCloser, error) {
 	if isUpdate() {
+		if err := os.MkdirAll(path.Dir(path.Join(ctx.prefix, filename)), 0700); err != nil {
+			return nil, err
+		}
 		return os.OpenFile(path.Join(ctx.prefix, filename), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
 	} else {
 		var file inMemoryFile
@@ -0,0 +1,18 @@
+package firemodel
+
+import (
+	"io"
+
+	"github.com/visor-tax/firemodel/internal/ast"
+)
+
+// FormatSchema returns the canonical formatting of the schema read from r, with the same comments.
+// The schema is only parsed, not compiled, so it may refer to types declared in other files.
+// Syntax errors are returned as Diagnostics.
+func FormatSchema(r io.Reader) ([]byte, error) {
+	tree, err := parseAST(r)
+	if err != nil {
+		return nil, err
+	}
+	return ast.Format(tree), nil
+}
@@ -0,0 +1,78 @@
+package firemodel
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/visor-tax/firemodel/internal/ast"
+	"gotest.tools/assert"
+)
+
+// TestFormatSchema checks that the schemas in testfixtures/schema are formatted as the files of the
+// same name in testfixtures/format.
+func TestFormatSchema(t *testing.T) {
+	for _, name := range []string{"full", "comments"} {
+		t.Run(name, func(t *testing.T) {
+			want, err := ioutil.ReadFile(path.Join("testfixtures", "format", name+".firemodel"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := FormatSchema(bytes.NewReader(mustReadFile(t, path.Join("testfixtures", "schema", name+".firemodel"))))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, string(got), string(want))
+		})
+	}
+}
+
+// TestFormatSchemaRoundTrip checks that formatting is idempotent, and that formatted schemas compile
+// to the same schema.
+func TestFormatSchemaRoundTrip(t *testing.T) {
+	paths, err := filepath.Glob(path.Join("testfixtures", "schema", "*.firemodel"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths = append(paths, path.Join("example", "firemodel.example.firemodel"), path.Join("example", "packages", "app.firemodel"))
+	for _, schemaPath := range paths {
+		t.Run(filepath.Base(schemaPath), func(t *testing.T) {
+			loader := newSchemaLoader()
+			loader.loadFile(schemaPath, nil)
+			if len(loader.diagnostics) > 0 {
+				t.Skip("schema doesn't parse")
+			}
+
+			var formatted []*ast.AST
+			for _, tree := range loader.files {
+				src := ast.Format(tree)
+				reparsed, err := ast.ParseSchema(bytes.NewReader(src))
+				if err != nil {
+					t.Fatalf("formatted schema doesn't parse: %v\n%s", err, src)
+				}
+				assert.Equal(t, string(ast.Format(reparsed)), string(src))
+				formatted = append(formatted, reparsed)
+			}
+
+			want, wantErr := compileSchema(loader.files...)
+			got, gotErr := compileSchema(formatted...)
+			if wantErr != nil {
+				assert.Assert(t, gotErr != nil)
+				return
+			}
+			assert.NilError(t, gotErr)
+			assert.DeepEqual(t, got, want, cmpopts.IgnoreTypes(Position{}))
+		})
+	}
+}
+
+func mustReadFile(t *testing.T, filename string) []byte {
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return src
+}
@@ -0,0 +1,289 @@
+package firemodel
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Declaration is a top-level declaration of a schema: a *SchemaModel, *SchemaStruct, *SchemaEnum,
+// *SchemaUnion or *SchemaAlias.
+type Declaration interface {
+	isDeclaration()
+}
+
+func (m *SchemaModel) isDeclaration()  {}
+func (s *SchemaStruct) isDeclaration() {}
+func (e *SchemaEnum) isDeclaration()   {}
+func (u *SchemaUnion) isDeclaration()  {}
+func (a *SchemaAlias) isDeclaration()  {}
+
+// DependencyKind is the way that one declaration depends on another.
+type DependencyKind int
+
+const (
+	// DependencyInline is a type that is stored inside the declaration, e.g. a struct field, or
+	// the items of an array field.
+	DependencyInline DependencyKind = iota
+	// DependencyExtends is a struct in the extends clause of the declaration.
+	DependencyExtends
+	// DependencyReference is the model of a reference<T> field.
+	DependencyReference
+	// DependencyCollection is the model of a collection<T> nested in a model.
+	DependencyCollection
+	// DependencyAlias is the alias that a field was declared with. The aliased type is a separate
+	// dependency.
+	DependencyAlias
+)
+
+func (kind DependencyKind) String() string {
+	switch kind {
+	case DependencyInline:
+		return "inline"
+	case DependencyExtends:
+		return "extends"
+	case DependencyReference:
+		return "reference"
+	case DependencyCollection:
+		return "collection"
+	case DependencyAlias:
+		return "alias"
+	default:
+		return fmt.Sprintf("dependency(%d)", int(kind))
+	}
+}
+
+// IsInline returns true if values of the declaration that has the dependency store the values it
+// depends on, which is the case for inline dependencies and extended structs.
+func (kind DependencyKind) IsInline() bool {
+	return kind == DependencyInline || kind == DependencyExtends
+}
+
+// Dependency is an edge of a DependencyGraph.
+type Dependency struct {
+	From Declaration
+	To   Declaration
+	Kind DependencyKind
+	// Name is the name of the field or collection that introduces the dependency, or "" for
+	// extended structs and the type of an alias.
+	Name string
+	Pos  Position
+}
+
+// DependencyGraph is the graph of the dependencies between the declarations of a schema.
+type DependencyGraph struct {
+	// Declarations are the nodes of the graph: the models, structs, enums, unions and aliases of
+	// the schema, in that order.
+	Declarations []Declaration
+
+	dependencies map[Declaration][]*Dependency
+	dependents   map[Declaration][]*Dependency
+}
+
+// Dependencies returns the dependencies of decl, in the order they are declared.
+func (g *DependencyGraph) Dependencies(decl Declaration) []*Dependency {
+	return g.dependencies[decl]
+}
+
+// Dependents returns the dependencies on decl.
+func (g *DependencyGraph) Dependents(decl Declaration) []*Dependency {
+	return g.dependents[decl]
+}
+
+// InlineCycles returns the cycles of inline dependencies, each starting with the dependency of the
+// first declaration of the cycle. A declaration on such a cycle contains itself, so its values
+// can't be stored.
+func (g *DependencyGraph) InlineCycles() (out [][]*Dependency) {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := map[Declaration]int{}
+	var path []*Dependency
+	var visit func(decl Declaration)
+	visit = func(decl Declaration) {
+		state[decl] = visiting
+		for _, dep := range g.dependencies[decl] {
+			if !dep.Kind.IsInline() {
+				continue
+			}
+			switch state[dep.To] {
+			case visiting:
+				// dep.To is on the path, or is decl itself.
+				start := len(path)
+				for idx, step := range path {
+					if step.From == dep.To {
+						start = idx
+						break
+					}
+				}
+				cycle := append([]*Dependency{}, path[start:]...)
+				out = append(out, append(cycle, dep))
+			case done:
+			default:
+				path = append(path, dep)
+				visit(dep.To)
+				path = path[:len(path)-1]
+			}
+		}
+		state[decl] = done
+	}
+	for _, decl := range g.Declarations {
+		if state[decl] == 0 {
+			visit(decl)
+		}
+	}
+	return
+}
+
+// DependencyGraph returns the graph of the dependencies between the declarations of the schema.
+func (s *Schema) DependencyGraph() *DependencyGraph {
+	g := &DependencyGraph{
+		dependencies: map[Declaration][]*Dependency{},
+		dependents:   map[Declaration][]*Dependency{},
+	}
+	var from Declaration
+	add := func(to Declaration, kind DependencyKind, name string, pos Position) {
+		dep := &Dependency{From: from, To: to, Kind: kind, Name: name, Pos: pos}
+		g.dependencies[from] = append(g.dependencies[from], dep)
+		g.dependents[to] = append(g.dependents[to], dep)
+	}
+	var addType func(fieldType SchemaFieldType, name string, pos Position)
+	addType = func(fieldType SchemaFieldType, name string, pos Position) {
+		switch fieldType := fieldType.(type) {
+		case *Array:
+			addType(fieldType.T, name, pos)
+		case *Map:
+			addType(fieldType.K, name, pos)
+			addType(fieldType.T, name, pos)
+		case *Struct:
+			if structType := s.Struct(fieldType.T.Package, fieldType.T.Name); structType != nil {
+				add(structType, DependencyInline, name, pos)
+			}
+		case *Union:
+			if union := s.Union(fieldType.T.Package, fieldType.T.Name); union != nil {
+				add(union, DependencyInline, name, pos)
+			}
+		case *Enum:
+			if enum := s.Enum(fieldType.T.Package, fieldType.T.Name); enum != nil {
+				add(enum, DependencyInline, name, pos)
+			}
+		case *Reference:
+			if fieldType.T == nil {
+				return
+			}
+			if model := s.Model(fieldType.T.Package, fieldType.T.Name); model != nil {
+				add(model, DependencyReference, name, pos)
+			}
+		}
+	}
+	addFields := func(extends []*SchemaStruct, fields []*SchemaField, pos Position) {
+		for _, parent := range extends {
+			if structType := s.Struct(parent.Package, parent.Name); structType != nil {
+				add(structType, DependencyExtends, "", pos)
+			}
+		}
+		for _, field := range fields {
+			if field.Origin != nil {
+				continue // a dependency of the struct it is inherited from
+			}
+			if field.Alias != nil {
+				if alias := s.Alias(field.Alias.Package, field.Alias.Name); alias != nil {
+					add(alias, DependencyAlias, field.Name, field.Pos)
+				}
+			}
+			addType(field.Type, field.Name, field.Pos)
+		}
+	}
+
+	for _, model := range s.Models {
+		from = model
+		g.Declarations = append(g.Declarations, model)
+		addFields(model.Extends, model.Fields, model.Pos)
+		for _, collection := range model.Collections {
+			if nested := s.Model(collection.Type.Package, collection.Type.Name); nested != nil {
+				add(nested, DependencyCollection, collection.Name, collection.Pos)
+			}
+		}
+	}
+	for _, structType := range s.Structs {
+		from = structType
+		g.Declarations = append(g.Declarations, structType)
+		addFields(structType.Extends, structType.Fields, structType.Pos)
+	}
+	for _, enum := range s.Enums {
+		g.Declarations = append(g.Declarations, enum)
+	}
+	for _, union := range s.Unions {
+		from = union
+		g.Declarations = append(g.Declarations, union)
+		addFields(nil, union.Members, union.Pos)
+	}
+	for _, alias := range s.Aliases {
+		from = alias
+		g.Declarations = append(g.Declarations, alias)
+		addType(alias.Type, "", alias.Pos)
+	}
+	return g
+}
+
+// checkCycles reports structs and unions that contain themselves. They are stored inline, so their
+// values would never end; only references and collections may refer back to a declaration.
+func (c *configSchemaCompiler) checkCycles(schema *Schema) {
+	for _, cycle := range schema.DependencyGraph().InlineCycles() {
+		if extendsOnly(cycle) {
+			continue // reported by inheritFields
+		}
+		steps := make([]string, len(cycle))
+		for idx, dep := range cycle {
+			if dep.Kind == DependencyExtends {
+				steps[idx] = fmt.Sprintf("%s extends %s", declarationName(dep.From), declarationName(dep.To))
+			} else {
+				steps[idx] = fmt.Sprintf("%s.%s stores %s", declarationName(dep.From), dep.Name, declarationName(dep.To))
+			}
+		}
+		c.errorAt(cycle[0].Pos, "%s %s contains itself (%s); use a reference<T> or collection<T> to refer back to it", declarationKind(cycle[0].From), declarationName(cycle[0].From), strings.Join(steps, ", "))
+	}
+}
+
+func extendsOnly(cycle []*Dependency) bool {
+	for _, dep := range cycle {
+		if dep.Kind != DependencyExtends {
+			return false
+		}
+	}
+	return true
+}
+
+func declarationName(decl Declaration) string {
+	switch decl := decl.(type) {
+	case *SchemaModel:
+		return decl.Name
+	case *SchemaStruct:
+		return decl.Name
+	case *SchemaEnum:
+		return decl.Name
+	case *SchemaUnion:
+		return decl.Name
+	case *SchemaAlias:
+		return decl.Name
+	default:
+		return ""
+	}
+}
+
+func declarationKind(decl Declaration) string {
+	switch decl.(type) {
+	case *SchemaModel:
+		return "model"
+	case *SchemaStruct:
+		return "struct"
+	case *SchemaEnum:
+		return "enum"
+	case *SchemaUnion:
+		return "union"
+	case *SchemaAlias:
+		return "type"
+	default:
+		return ""
+	}
+}
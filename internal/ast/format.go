@@ -0,0 +1,292 @@
+package ast
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/participle/lexer"
+)
+
+// indent is the indentation of the elements of a declaration.
+const indent = "  "
+
+var (
+	intPattern    = regexp.MustCompile(`^[0-9]+$`)
+	numberPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+)
+
+// Format returns the canonical source of a parsed schema file.
+//
+// The package declaration comes first, followed by the imports and the file options, and then the
+// other declarations in the order they were declared, separated by blank lines. Options come
+// first in models and unions, followed by reserved names and then the fields, and enums list
+// their reserved values before their values. The order of fields and enum values is kept, along
+// with the blank lines between them, since it is the order of the generated code.
+//
+// Comments are kept with the element that follows them, except for a comment at the end of a line,
+// which stays at the end of the line of the element before it. A blank line after a comment, such
+// as the one that separates a file header from the first declaration, is kept. Option values are
+// written without quotes when that doesn't change their meaning, e.g. `true` and `3`.
+func Format(tree *AST) []byte {
+	var (
+		pkg     []*ASTElement
+		imports []*ASTElement
+		options []*ASTElement
+		decls   []*ASTElement
+	)
+	for _, element := range tree.Types {
+		switch {
+		case element.Package != nil:
+			pkg = append(pkg, element)
+		case element.Import != nil:
+			imports = append(imports, element)
+		case element.Option != nil:
+			options = append(options, element)
+		default:
+			decls = append(decls, element)
+		}
+	}
+
+	out := &strings.Builder{}
+	for _, group := range [][]*ASTElement{pkg, imports, options} {
+		for idx, element := range group {
+			trailing, leading := tree.splitComments(element.Comments)
+			formatTrailing(out, trailing)
+			if idx == 0 && out.Len() > 0 {
+				out.WriteString("\n")
+			}
+			formatComments(out, tree, "", leading)
+			switch {
+			case element.Package != nil:
+				fmt.Fprintf(out, "package %s;\n", element.Package.Name)
+			case element.Import != nil:
+				fmt.Fprintf(out, "import %s;\n", strconv.Quote(element.Import.Path))
+			case element.Option != nil:
+				fmt.Fprintf(out, "option %s;\n", formatOption(element.Option))
+			}
+		}
+	}
+	for _, element := range decls {
+		trailing, leading := tree.splitComments(element.Comments)
+		formatTrailing(out, trailing)
+		if out.Len() > 0 {
+			out.WriteString("\n")
+		}
+		formatComments(out, tree, "", leading)
+		switch {
+		case element.Model != nil:
+			formatModel(out, tree, "model", element.Model.Identifier, element.Model.Extends, element.Model.Elements)
+		case element.Struct != nil:
+			var elements []*ASTModelElement
+			for _, element := range element.Struct.Elements {
+				elements = append(elements, &ASTModelElement{Reserved: element.Reserved, Field: element.Field})
+			}
+			formatModel(out, tree, "struct", element.Struct.Identifier, element.Struct.Extends, elements)
+		case element.Union != nil:
+			formatModel(out, tree, "union", element.Union.Identifier, nil, element.Union.Elements)
+		case element.Enum != nil:
+			formatEnum(out, tree, element.Enum)
+		case element.Alias != nil:
+			alias := element.Alias
+			fmt.Fprintf(out, "type %s = %s%s;\n", alias.Identifier, alias.Type, formatFieldOptions(alias.Options))
+		}
+	}
+	return []byte(out.String())
+}
+
+// formatModel formats a model, or a struct or union, which have the same elements.
+func formatModel(out *strings.Builder, tree *AST, keyword string, name ASTIdentifier, extends []*ASTFieldType, elements []*ASTModelElement) {
+	fmt.Fprintf(out, "%s %s", keyword, name)
+	if len(extends) > 0 {
+		parents := make([]string, len(extends))
+		for idx, parent := range extends {
+			parents[idx] = parent.String()
+		}
+		fmt.Fprintf(out, " extends %s", strings.Join(parents, ", "))
+	}
+	if len(elements) == 0 {
+		out.WriteString(" {}\n")
+		return
+	}
+	out.WriteString(" {\n")
+
+	var (
+		options  []*ASTModelElement
+		reserved []*ASTReserved
+		fields   []*ASTField
+	)
+	for _, element := range elements {
+		switch {
+		case element.Option != nil:
+			options = append(options, element)
+		case element.Reserved != nil:
+			reserved = append(reserved, element.Reserved)
+		case element.Field != nil:
+			fields = append(fields, element.Field)
+		}
+	}
+	for _, element := range options {
+		trailing, leading := tree.splitComments(element.Comments)
+		formatTrailing(out, trailing)
+		formatComments(out, tree, indent, leading)
+		fmt.Fprintf(out, "%soption %s;\n", indent, formatOption(element.Option))
+	}
+	for _, reserved := range reserved {
+		formatReserved(out, tree, reserved)
+	}
+	blank := len(options) > 0 || len(reserved) > 0
+	for idx, field := range fields {
+		trailing, leading := tree.splitComments(field.Comments)
+		formatTrailing(out, trailing)
+		if blank || (idx > 0 && tree.blankBefore(field.Pos, trailing)) {
+			out.WriteString("\n")
+		}
+		blank = false
+		formatComments(out, tree, indent, leading)
+		out.WriteString(indent)
+		if field.Modifier != "" {
+			out.WriteString(field.Modifier + " ")
+		}
+		out.WriteString(field.Type.String())
+		if field.Nullable {
+			out.WriteString("?")
+		}
+		out.WriteString(" " + field.Name)
+		if field.Default != nil {
+			out.WriteString(" = " + field.Default.String())
+		}
+		out.WriteString(formatFieldOptions(field.Options) + ";\n")
+	}
+	out.WriteString("}\n")
+}
+
+func formatEnum(out *strings.Builder, tree *AST, enum *ASTEnum) {
+	fmt.Fprintf(out, "enum %s", enum.Identifier)
+	if enum.Backing != "" {
+		fmt.Fprintf(out, " : %s", enum.Backing)
+	}
+	if len(enum.Reserved) == 0 && len(enum.Values) == 0 {
+		out.WriteString(" {}\n")
+		return
+	}
+	out.WriteString(" {\n")
+
+	for _, reserved := range enum.Reserved {
+		formatReserved(out, tree, reserved)
+	}
+	blank := len(enum.Reserved) > 0
+	for idx, value := range enum.Values {
+		trailing, leading := tree.splitComments(value.Comments)
+		formatTrailing(out, trailing)
+		if blank || (idx > 0 && tree.blankBefore(value.Pos, trailing)) {
+			out.WriteString("\n")
+		}
+		blank = false
+		formatComments(out, tree, indent, leading)
+		out.WriteString(indent + value.Name)
+		if value.Value != nil {
+			out.WriteString(" = " + value.Value.String())
+		}
+		out.WriteString(formatFieldOptions(value.Options) + ",\n")
+	}
+	out.WriteString("}\n")
+}
+
+// splitComments splits the comments before an element into the comment at the end of the line of
+// the element before it, if there is one, and the comment of the element.
+func (t *AST) splitComments(comments []*ASTComment) (trailing, leading []*ASTComment) {
+	if len(comments) > 0 && t.code[comments[0].Pos.Line] {
+		return comments[:1], comments[1:]
+	}
+	return nil, comments
+}
+
+// blankBefore returns true if there is a blank line before the element at pos, whose comments
+// start with trailing.
+func (t *AST) blankBefore(pos lexer.Position, trailing []*ASTComment) bool {
+	if t.lines == nil {
+		return false
+	}
+	if len(trailing) > 0 {
+		return !t.lines[pos.Line+1]
+	}
+	return pos.Line > 1 && !t.lines[pos.Line-1]
+}
+
+// blankAfter returns true if line is followed by a blank line.
+func (t *AST) blankAfter(line int) bool {
+	return t.lines != nil && !t.lines[line+1]
+}
+
+func formatComments(out *strings.Builder, tree *AST, prefix string, comments []*ASTComment) {
+	for _, comment := range comments {
+		out.WriteString(prefix + formatComment(comment) + "\n")
+		if tree.blankAfter(comment.Pos.Line) {
+			out.WriteString("\n")
+		}
+	}
+}
+
+// formatTrailing writes comments at the end of the last line written to out.
+func formatTrailing(out *strings.Builder, comments []*ASTComment) {
+	if len(comments) == 0 {
+		return
+	}
+	written := strings.TrimSuffix(out.String(), "\n")
+	out.Reset()
+	out.WriteString(written)
+	for _, comment := range comments {
+		if written != "" {
+			out.WriteString(" ")
+		}
+		out.WriteString(formatComment(comment))
+	}
+	out.WriteString("\n")
+}
+
+func formatComment(comment *ASTComment) string {
+	if comment.Text == "" {
+		return "//"
+	}
+	return "// " + comment.Text
+}
+
+func formatOption(option *ASTOption) string {
+	value := option.Value
+	if !isKeyword(value) && !intPattern.MatchString(value) {
+		value = strconv.Quote(value)
+	}
+	return fmt.Sprintf("%s.%s = %s", option.Language, option.Key, value)
+}
+
+func formatFieldOptions(options []*ASTFieldOption) string {
+	if len(options) == 0 {
+		return ""
+	}
+	formatted := make([]string, len(options))
+	for idx, option := range options {
+		value := option.Value
+		if !isKeyword(value) && !numberPattern.MatchString(value) {
+			value = strconv.Quote(value)
+		}
+		formatted[idx] = fmt.Sprintf("%s = %s", option.Key, value)
+	}
+	return fmt.Sprintf(" [%s]", strings.Join(formatted, ", "))
+}
+
+func formatReserved(out *strings.Builder, tree *AST, reserved *ASTReserved) {
+	values := make([]string, len(reserved.Values))
+	for idx, value := range reserved.Values {
+		values[idx] = value.String()
+	}
+	trailing, leading := tree.splitComments(reserved.Comments)
+	formatTrailing(out, trailing)
+	formatComments(out, tree, indent, leading)
+	fmt.Fprintf(out, "%sreserved %s;\n", indent, strings.Join(values, ", "))
+}
+
+func isKeyword(value string) bool {
+	return value == "true" || value == "false" || value == "null"
+}
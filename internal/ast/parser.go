@@ -14,9 +14,10 @@ import (
 )
 
 func ParseSchema(r io.Reader) (*AST, error) {
+	definition := &lexerDefinition{}
 	parser := participle.MustBuild(
 		&AST{},
-		participle.Lexer(&lexerDefinition{}),
+		participle.Lexer(definition),
 		participle.Map(func(token lexer.Token) (lexer.Token, error) {
 			if token.Type == scanner.Comment {
 				p := regexp.MustCompile(`//\s*(?P<Comment>.*)`)
@@ -30,16 +31,87 @@ func ParseSchema(r io.Reader) (*AST, error) {
 	if err := parser.Parse(r, s); err != nil {
 		return nil, errors.Wrap(err, "firemodel:")
 	}
+	moveComments(s)
+	s.lines, s.code = definition.lines, definition.code
 	return s, nil
 }
 
-type lexerDefinition struct{}
+// moveComments moves the comments of the elements of structs, models, unions and enums to the
+// reserved statement, field or enum value they belong to, which then starts at its comments like
+// a declaration does. It sorts the elements of enums by kind.
+func moveComments(tree *AST) {
+	for _, element := range tree.Types {
+		switch {
+		case element.Model != nil:
+			for _, element := range element.Model.Elements {
+				moveModelComments(element)
+			}
+		case element.Union != nil:
+			for _, element := range element.Union.Elements {
+				moveModelComments(element)
+			}
+		case element.Struct != nil:
+			for _, element := range element.Struct.Elements {
+				if element.Reserved != nil {
+					element.Reserved.Pos, element.Reserved.Comments = element.Pos, element.Comments
+				} else {
+					element.Field.Pos, element.Field.Comments = element.Pos, element.Comments
+				}
+			}
+		case element.Enum != nil:
+			enum := element.Enum
+			for _, element := range enum.Elements {
+				if element.Reserved != nil {
+					element.Reserved.Pos, element.Reserved.Comments = element.Pos, element.Comments
+					enum.Reserved = append(enum.Reserved, element.Reserved)
+				} else {
+					element.Value.Pos, element.Value.Comments = element.Pos, element.Comments
+					enum.Values = append(enum.Values, element.Value)
+				}
+			}
+		}
+	}
+}
+
+func moveModelComments(element *ASTModelElement) {
+	switch {
+	case element.Reserved != nil:
+		element.Reserved.Pos, element.Reserved.Comments = element.Pos, element.Comments
+	case element.Field != nil:
+		element.Field.Pos, element.Field.Comments = element.Pos, element.Comments
+	}
+}
+
+type lexerDefinition struct {
+	// lines and code are the lines of the last lexed file that have tokens, and that have tokens
+	// other than comments.
+	lines map[int]bool
+	code  map[int]bool
+}
 
 func (d *lexerDefinition) Lex(r io.Reader) (lexer.Lexer, error) {
 	s := &scanner.Scanner{}
 	l := lexer.LexWithScanner(r, s)
 	s.Mode = s.Mode &^ scanner.SkipComments
-	return l, nil
+	d.lines, d.code = map[int]bool{}, map[int]bool{}
+	return &lineLexer{Lexer: l, definition: d}, nil
+}
+
+// lineLexer records the lines that have tokens.
+type lineLexer struct {
+	lexer.Lexer
+	definition *lexerDefinition
+}
+
+func (l *lineLexer) Next() (lexer.Token, error) {
+	token, err := l.Lexer.Next()
+	if err == nil && !token.EOF() {
+		l.definition.lines[token.Pos.Line] = true
+		if token.Type != scanner.Comment {
+			l.definition.code[token.Pos.Line] = true
+		}
+	}
+	return token, err
 }
 
 func (d *lexerDefinition) Symbols() map[string]rune {
@@ -48,6 +120,7 @@ func (d *lexerDefinition) Symbols() map[string]rune {
 		"Ident":   scanner.Ident,
 		"String":  scanner.String,
 		"Int":     scanner.Int,
+		"Float":   scanner.Float,
 		"Comment": scanner.Comment,
 	}
 }
@@ -57,26 +130,85 @@ func (d *lexerDefinition) Symbols() map[string]rune {
 // Read about the magical annotations here: https://github.com/alecthomas/participle/.
 type AST struct {
 	Types []*ASTElement `parser:"{ @@ }"`
+
+	// lines and code are the lines that have tokens, and that have tokens other than comments.
+	// They are used to keep the layout of comments when formatting.
+	lines map[int]bool
+	code  map[int]bool
+}
+
+// ASTComment is a line of a comment, without the leading //.
+type ASTComment struct {
+	Pos lexer.Position
+
+	Text string `parser:"@Comment"`
 }
 
 type ASTElement struct {
-	Comment string     `parser:"{ @Comment }"`
-	Model   *ASTModel  `parser:"(  'model' @@"`
-	Enum    *ASTEnum   `parser:"| 'enum' @@"`
-	Option  *ASTOption `parser:"| 'option' @@"`
-	Struct  *ASTStruct `parser:"| 'struct' @@ )"`
+	Pos lexer.Position
+
+	// Comments are the lines of the comment before the element.
+	Comments []*ASTComment `parser:"{ @@ }"`
+	Model    *ASTModel     `parser:"(  'model' @@"`
+	Enum     *ASTEnum      `parser:"| 'enum' @@"`
+	Option   *ASTOption    `parser:"| 'option' @@"`
+	Struct   *ASTStruct    `parser:"| 'struct' @@"`
+	Union    *ASTUnion     `parser:"| 'union' @@"`
+	Alias    *ASTAlias     `parser:"| 'type' @@"`
+	Import   *ASTImport    `parser:"| 'import' @@"`
+	Package  *ASTPackage   `parser:"| 'package' @@ )"`
+}
+
+// ASTPackage declares the package that every declaration in a file belongs to.
+type ASTPackage struct {
+	Pos lexer.Position
+
+	Name ASTIdentifier `parser:"@Ident ';'"`
+}
+
+// ASTImport pulls the declarations of another schema file, resolved relative to the importing
+// file, into the schema.
+type ASTImport struct {
+	Pos lexer.Position
+
+	Path string `parser:"@String ';'"`
 }
 
 type ASTModel struct {
+	Pos lexer.Position
+
 	Identifier ASTIdentifier      `parser:"@Ident"`
+	Extends    []*ASTFieldType    `parser:"[ 'extends' @@ { ',' @@ } ]"`
 	Elements   []*ASTModelElement `parser:"'{' { @@ } '}'"`
 }
 
 type ASTStruct struct {
+	Pos lexer.Position
+
 	Identifier ASTIdentifier       `parser:"@Ident"`
+	Extends    []*ASTFieldType     `parser:"[ 'extends' @@ { ',' @@ } ]"`
 	Elements   []*ASTStructElement `parser:"'{' { @@ } '}'"`
 }
 
+// ASTUnion is a value that holds exactly one of its members, each of which is a struct. Unions may
+// have options like models.
+type ASTUnion struct {
+	Pos lexer.Position
+
+	Identifier ASTIdentifier      `parser:"@Ident"`
+	Elements   []*ASTModelElement `parser:"'{' { @@ } '}'"`
+}
+
+// ASTAlias declares a name for another type, along with options that apply wherever it is used,
+// e.g. `type Email = string [pattern = "^.+@.+$"];`.
+type ASTAlias struct {
+	Pos lexer.Position
+
+	Identifier ASTIdentifier     `parser:"@Ident '='"`
+	Type       *ASTFieldType     `parser:"@@"`
+	Options    []*ASTFieldOption `parser:"[ '[' @@ { ',' @@ } ']' ] ';'"`
+}
+
 type ASTIdentifier string
 
 var (
@@ -86,64 +218,184 @@ var (
 		"bytes", "reference", "geopoint", "array", "map", "url",
 		"file", "collection",
 		// Keywords.
-		"model", "option", "enum",
+		"model", "option", "enum", "union",
+	}
+	// Statement keywords can start a field where its type is expected, so a type with the same
+	// name couldn't be used. Unlike reserved identifiers, they are only keywords in lower case,
+	// and they are still allowed as option keys.
+	statementKeywords = []string{
+		"optional", "required", "reserved",
 	}
 )
 
 func init() {
 	sort.Strings(reservedIdentifiers)
+	sort.Strings(statementKeywords)
 }
 
 func (id ASTIdentifier) IsReserved() bool {
-	needle := strings.ToLower(string(id))
-	idx := sort.SearchStrings(reservedIdentifiers, needle)
-	if idx >= len(reservedIdentifiers) {
+	if id.IsReservedOptionKey() {
+		return true
+	}
+	idx := sort.SearchStrings(statementKeywords, string(id))
+	return idx < len(statementKeywords) && statementKeywords[idx] == string(id)
+}
+
+// IsReservedOptionKey returns true if id can't be used as the key of an option.
+func (id ASTIdentifier) IsReservedOptionKey() bool {
+	return contains(reservedIdentifiers, string(id))
+}
+
+func contains(sorted []string, id string) bool {
+	needle := strings.ToLower(id)
+	idx := sort.SearchStrings(sorted, needle)
+	if idx >= len(sorted) {
 		return false
 	}
-	if reservedIdentifiers[idx] != needle {
+	if sorted[idx] != needle {
 		return false
 	}
 	return true
 }
 
+// The elements of structs, models, unions and enums parse the comments before them, rather than
+// each kind of element, so that telling the kinds apart doesn't need more than a token of
+// lookahead. moveComments moves them to the element after parsing.
+
 type ASTStructElement struct {
-	Field *ASTField `parser:"@@"`
+	Pos lexer.Position
+
+	Comments []*ASTComment `parser:"{ @@ }"`
+	Reserved *ASTReserved  `parser:"(  'reserved' @@"`
+	Field    *ASTField     `parser:"| @@ )"`
 }
 
 type ASTModelElement struct {
-	Option *ASTOption `parser:"  'option' @@"`
-	Field  *ASTField  `parser:"| @@"`
+	Pos lexer.Position
+
+	Comments []*ASTComment `parser:"{ @@ }"`
+	Option   *ASTOption    `parser:"(  'option' @@"`
+	Reserved *ASTReserved  `parser:"| 'reserved' @@"`
+	Field    *ASTField     `parser:"| @@ )"`
+}
+
+// ASTReserved reserves the names of removed fields, or the values of removed enum values, so that
+// they aren't reused, e.g. `reserved "old_name", "older_name";`.
+type ASTReserved struct {
+	Pos lexer.Position
+
+	Comments []*ASTComment
+	Values   []*ASTValue `parser:"@@ { ',' @@ } ';'"`
 }
 
 type ASTEnum struct {
-	Identifier ASTIdentifier   `parser:"@Ident '{'"`
-	Values     []*ASTEnumValue `parser:"{ @@ } '}'"`
+	Pos lexer.Position
+
+	Identifier ASTIdentifier     `parser:"@Ident"`
+	Backing    string            `parser:"[ ':' @Ident ] '{'"`
+	Elements   []*ASTEnumElement `parser:"{ @@ } '}'"`
+
+	// Reserved and Values are the elements of the enum by kind.
+	Reserved []*ASTReserved
+	Values   []*ASTEnumValue
+}
+
+type ASTEnumElement struct {
+	Pos lexer.Position
+
+	Comments []*ASTComment `parser:"{ @@ }"`
+	Reserved *ASTReserved  `parser:"(  'reserved' @@"`
+	Value    *ASTEnumValue `parser:"| @@ )"`
 }
 
 type ASTOption struct {
+	Pos lexer.Position
+
 	Language string        `parser:"@Ident '.'"`
 	Key      ASTIdentifier `parser:"@Ident '='"`
 	Value    string        `parser:"@('true' | 'false' | 'null' | String | Int) ';'"`
 }
 
 type ASTEnumValue struct {
-	Comment string `parser:"{ @Comment }"`
-	Name    string `parser:"@Ident ','"`
+	Pos lexer.Position
+
+	Comments []*ASTComment
+	Name     string            `parser:"@Ident"`
+	Value    *ASTValue         `parser:"[ '=' @@ ]"`
+	Options  []*ASTFieldOption `parser:"[ '[' @@ { ',' @@ } ']' ] ','"`
 }
 
 type ASTField struct {
-	Comment string        `parser:"{ @Comment }"`
-	Type    *ASTFieldType `parser:"@@"`
-	Name    string        `parser:"@Ident ';'"`
+	Pos lexer.Position
+
+	Comments []*ASTComment
+	Modifier string            `parser:"[ @('required' | 'optional') ]"`
+	Type     *ASTFieldType     `parser:"@@"`
+	Nullable bool              `parser:"[ @'?' ]"`
+	Name     string            `parser:"@Ident"`
+	Default  *ASTValue         `parser:"[ '=' @@ ]"`
+	Options  []*ASTFieldOption `parser:"[ '[' @@ { ',' @@ } ']' ] ';'"`
+}
+
+// ASTValue is a literal value, e.g. the default value of a field. Identifiers refer to enum
+// values.
+type ASTValue struct {
+	Pos lexer.Position
+
+	Str    *string `parser:"  @String"`
+	Number *string `parser:"| @( [ '-' ] ( Int | Float ) )"`
+	Bool   *string `parser:"| @( 'true' | 'false' )"`
+	Ident  *string `parser:"| @Ident"`
+}
+
+func (v *ASTValue) String() string {
+	switch {
+	case v.Str != nil:
+		return fmt.Sprintf("%q", *v.Str)
+	case v.Number != nil:
+		return *v.Number
+	case v.Bool != nil:
+		return *v.Bool
+	case v.Ident != nil:
+		return *v.Ident
+	default:
+		return ""
+	}
+}
+
+// ASTFieldOption is an option attached to a single field, e.g. `[firestore.name = "displayName"]`.
+// The key may be namespaced like model options, or bare.
+type ASTFieldOption struct {
+	Pos lexer.Position
+
+	Key   string `parser:"@Ident { @'.' @Ident }"`
+	Value string `parser:"'=' @('true' | 'false' | 'null' | String | [ '-' ] ( Int | Float ))"`
+}
+
+// Namespaced splits the key of the option into its namespace and name. The namespace of a bare key
+// is "".
+func (o *ASTFieldOption) Namespaced() (namespace string, key ASTIdentifier) {
+	if idx := strings.LastIndex(o.Key, "."); idx >= 0 {
+		return o.Key[:idx], ASTIdentifier(o.Key[idx+1:])
+	}
+	return "", ASTIdentifier(o.Key)
 }
 
 type ASTFieldType struct {
-	Base    ASTType       `parser:"@Ident"`
-	Generic *ASTFieldType `parser:"[ '<' @@ '>' ]"`
+	Pos lexer.Position
+
+	Base ASTType `parser:"@Ident { @'.' @Ident }"`
+	// Generic is the type parameter, e.g. string in array<string>. In map<K, V>, it is the key
+	// type K.
+	Generic *ASTFieldType `parser:"[ '<' @@"`
+	// Value is the second type parameter, e.g. the value type V in map<K, V>.
+	Value *ASTFieldType `parser:"[ ',' @@ ] '>' ]"`
 }
 
 func (ft *ASTFieldType) String() string {
-	if ft.Generic != nil {
+	if ft.Value != nil {
+		return fmt.Sprintf("%s<%s, %s>", ft.Base, ft.Generic, ft.Value)
+	} else if ft.Generic != nil {
 		return fmt.Sprintf("%s<%s>", ft.Base, ft.Generic)
 	} else {
 		return fmt.Sprint(ft.Base)
@@ -193,3 +445,13 @@ const (
 func (s ASTType) IsCollection() bool {
 	return s == collection
 }
+
+// Qualified splits a package-qualified type name, such as billing.Invoice, into its package and
+// name. Unqualified types have an empty package.
+func (s ASTType) Qualified() (pkg string, name string) {
+	idx := strings.LastIndex(string(s), ".")
+	if idx < 0 {
+		return "", string(s)
+	}
+	return string(s[:idx]), string(s[idx+1:])
+}
@@ -0,0 +1,117 @@
+// Package diff formats the differences between two texts as a unified diff.
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// context is the number of unchanged lines around each change.
+const context = 3
+
+// line is a line of either text, including its newline if it has one.
+type line struct {
+	op   diffmatchpatch.Operation
+	text string
+}
+
+// Unified returns the unified diff that turns text a, named fromName, into text b, named toName.
+// It returns "" if the texts are equal.
+func Unified(fromName, toName, a, b string) string {
+	if a == b {
+		return ""
+	}
+
+	dmp := diffmatchpatch.New()
+	charsA, charsB, lineArray := dmp.DiffLinesToChars(a, b)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(charsA, charsB, false), lineArray)
+	var lines []line
+	for _, d := range diffs {
+		for _, text := range splitLines(d.Text) {
+			lines = append(lines, line{d.Type, text})
+		}
+	}
+
+	out := &strings.Builder{}
+	fmt.Fprintf(out, "--- %s\n+++ %s\n", fromName, toName)
+	// lineA and lineB are the (0-based) numbers of lines[idx] in a and b.
+	idx, lineA, lineB := 0, 0, 0
+	for idx < len(lines) {
+		if lines[idx].op == diffmatchpatch.DiffEqual {
+			idx, lineA, lineB = idx+1, lineA+1, lineB+1
+			continue
+		}
+
+		// A hunk starts with up to context equal lines, and continues until a change is followed
+		// by more than 2*context equal lines, the last context of which it ends with.
+		start := idx
+		for start > 0 && idx-start < context && lines[start-1].op == diffmatchpatch.DiffEqual {
+			start--
+		}
+		startA, startB := lineA-(idx-start), lineB-(idx-start)
+		last := idx
+		for next, equal := idx, 0; next < len(lines) && equal <= 2*context; next++ {
+			if lines[next].op == diffmatchpatch.DiffEqual {
+				equal++
+			} else {
+				last, equal = next, 0
+			}
+		}
+		end := last + 1
+		for end < len(lines) && end-last <= context && lines[end].op == diffmatchpatch.DiffEqual {
+			end++
+		}
+
+		hunk := &strings.Builder{}
+		countA, countB := 0, 0
+		for _, l := range lines[start:end] {
+			prefix := " "
+			switch l.op {
+			case diffmatchpatch.DiffDelete:
+				prefix = "-"
+				countA++
+			case diffmatchpatch.DiffInsert:
+				prefix = "+"
+				countB++
+			default:
+				countA++
+				countB++
+			}
+			hunk.WriteString(prefix + l.text)
+			if !strings.HasSuffix(l.text, "\n") {
+				hunk.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		fmt.Fprintf(out, "@@ -%s +%s @@\n%s", hunkRange(startA, countA), hunkRange(startB, countB), hunk)
+
+		idx, lineA, lineB = end, startA+countA, startB+countB
+	}
+	return out.String()
+}
+
+// hunkRange formats the range of count lines from the (0-based) line start.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		// An empty range refers to the line before it.
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// splitLines splits text after every newline.
+func splitLines(text string) (out []string) {
+	for text != "" {
+		idx := strings.IndexByte(text, '\n')
+		if idx < 0 {
+			return append(out, text)
+		}
+		out = append(out, text[:idx+1])
+		text = text[idx+1:]
+	}
+	return
+}
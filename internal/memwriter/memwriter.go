@@ -0,0 +1,54 @@
+// Package memwriter implements a firemodel.SourceCoder that keeps the generated files in memory.
+package memwriter
+
+import (
+	"bytes"
+	"io"
+)
+
+func New(prefix string) *MemWriter {
+	return &MemWriter{
+		prefix: prefix,
+		files:  map[string]*file{},
+	}
+}
+
+// MemWriter collects the files written to it instead of writing them to the output directory.
+type MemWriter struct {
+	prefix string
+	files  map[string]*file
+}
+
+type file struct {
+	bytes.Buffer
+}
+
+func (f *file) Close() error {
+	return nil
+}
+
+func (w *MemWriter) NewFile(filename string) (io.WriteCloser, error) {
+	f := &file{}
+	w.files[filename] = f
+	return f, nil
+}
+
+// Flush does nothing; the files stay in memory.
+func (w *MemWriter) Flush() error {
+	return nil
+}
+
+// Prefix returns the output directory that the files would have been written to.
+func (w *MemWriter) Prefix() string {
+	return w.prefix
+}
+
+// Files returns the contents of the files written so far, by name relative to the output
+// directory.
+func (w *MemWriter) Files() map[string][]byte {
+	out := make(map[string][]byte, len(w.files))
+	for filename, f := range w.files {
+		out[filename] = f.Bytes()
+	}
+	return out
+}
@@ -0,0 +1,8 @@
+package memwriter_test
+
+import (
+	"github.com/visor-tax/firemodel"
+	"github.com/visor-tax/firemodel/internal/memwriter"
+)
+
+var _ firemodel.SourceCoder = &memwriter.MemWriter{}
@@ -1,55 +1,327 @@
+// Package tempwriter implements a firemodel.SourceCoder that writes the generated files to the
+// output directory when it is flushed, leaving the files that didn't change untouched.
 package tempwriter
 
 import (
+	"bufio"
+	"bytes"
 	"io"
 	"io/ioutil"
 	"os"
-	"path"
 	"path/filepath"
+	"sort"
+	"strings"
 )
 
+// ManifestName is the name of the file in the output directory that lists the files generated in
+// it, so that the next run can remove the ones that are no longer generated.
+const ManifestName = ".firemodel-manifest"
+
+// manifestHeader is the first line of the manifest.
+const manifestHeader = "# Files generated by firemodel. Do not edit."
+
 func New(prefix string, wipe bool) *TempWriter {
 	return &TempWriter{
 		prefix: prefix,
-		files:  map[string]*os.File{},
+		files:  map[string]*file{},
 		wipe:   wipe,
 	}
 }
 
+// TempWriter keeps the generated files in memory until it is flushed.
 type TempWriter struct {
 	prefix string
-	files  map[string]*os.File
+	files  map[string]*file
 	wipe   bool
+
+	written []string
+	removed []string
+}
+
+type file struct {
+	bytes.Buffer
+}
+
+func (f *file) Close() error {
+	return nil
 }
 
 func (w *TempWriter) NewFile(filename string) (io.WriteCloser, error) {
-	dir := path.Join(os.TempDir(), w.prefix)
-	if err := os.MkdirAll(dir, 0700); err != nil {
-		return nil, err
+	f := &file{}
+	w.files[filepath.Clean(filename)] = f
+	return f, nil
+}
+
+// Flush updates the output directory: files whose contents changed are written, and the files
+// listed in the manifest of the previous run that are no longer generated are removed. If wipe is
+// set, every other file that is not generated is removed too.
+//
+// The new output directory is staged next to the old one and then swapped in for it, so that a
+// failure, e.g. running out of disk space, leaves the output directory as it was. Files that are
+// kept are linked into the staged directory rather than copied, so that files whose contents
+// didn't change keep their modification time. Nothing is staged if no file changed.
+func (w *TempWriter) Flush() error {
+	w.written, w.removed = nil, nil
+	var filenames []string
+	for filename := range w.files {
+		filenames = append(filenames, filename)
 	}
-	f, err := ioutil.TempFile(dir, filename)
+	sort.Strings(filenames)
+
+	removed, err := Stale(w.prefix, filenames, w.wipe)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	manifestPath, contents := filepath.Join(w.prefix, ManifestName), manifest(filenames)
+	skip := map[string]bool{manifestPath: true}
+	for _, path := range removed {
+		skip[path] = true
+	}
+	var changed, written []string
+	for _, filename := range filenames {
+		path := filepath.Join(w.prefix, filename)
+		if !unchanged(path, w.files[filename].Bytes()) {
+			changed = append(changed, filename)
+			written = append(written, path)
+			skip[path] = true
+		}
+	}
+	if len(written) == 0 && len(removed) == 0 && unchanged(manifestPath, contents) {
+		return nil
 	}
-	w.files[filename] = f
 
-	return f, nil
+	dir, err := stage(w.prefix, skip)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	for _, filename := range changed {
+		if err := writeFile(filepath.Join(dir, filename), w.files[filename].Bytes()); err != nil {
+			return err
+		}
+	}
+	if err := writeFile(filepath.Join(dir, ManifestName), contents); err != nil {
+		return err
+	}
+	for _, path := range removed {
+		rel, err := filepath.Rel(w.prefix, path)
+		if err != nil {
+			return err
+		}
+		removeEmptyDirs(dir, filepath.Dir(filepath.Join(dir, rel)))
+	}
+	if err := swap(dir, w.prefix); err != nil {
+		return err
+	}
+	w.written, w.removed = written, removed
+	return nil
 }
 
-func (w *TempWriter) Flush() error {
-	if w.wipe {
-		os.RemoveAll(w.prefix)
+// stage creates a directory next to the output directory prefix with its directories, and its files
+// but the ones in skip. It returns the path of the staged directory.
+func stage(prefix string, skip map[string]bool) (string, error) {
+	prefix = filepath.Clean(prefix)
+	abs, err := filepath.Abs(prefix)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(abs), 0777); err != nil {
+		return "", err
 	}
-	if err := os.MkdirAll(w.prefix, 0777); err != nil {
-		panic(err)
+	dir, err := ioutil.TempDir(filepath.Dir(abs), "."+filepath.Base(abs)+".")
+	if err != nil {
+		return "", err
+	}
+	if err := os.Chmod(dir, 0755); err != nil {
+		os.RemoveAll(dir)
+		return "", err
 	}
-	for filename, f := range w.files {
-		if err := os.Rename(
-			f.Name(),                          // in tempdir
-			filepath.Join(w.prefix, filename), // in target dir
-		); err != nil {
+	err = filepath.Walk(prefix, func(path string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(prefix, path)
+		if err != nil {
 			return err
 		}
+		target := filepath.Join(dir, rel)
+		if info.IsDir() {
+			if err := os.MkdirAll(target, 0777); err != nil {
+				return err
+			}
+			return os.Chmod(target, info.Mode().Perm())
+		} else if skip[path] {
+			return nil
+		}
+		return keep(path, target, info)
+	})
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return dir, nil
+}
+
+// keep puts the file at path, described by info, at target in the staged directory.
+func keep(path, target string, info os.FileInfo) error {
+	if err := os.Link(path, target); err == nil || !info.Mode().IsRegular() {
+		return err
+	}
+	// Not every file system supports hard links.
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(target, contents, info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Chtimes(target, info.ModTime(), info.ModTime())
+}
+
+// swap replaces the directory prefix with the staged directory dir. A directory can't be renamed
+// over one that isn't empty, so the old one is renamed out of the way first, and put back if dir
+// can't be renamed into place. The output directory is missing in between, but it is never half
+// written.
+func swap(dir, prefix string) error {
+	old := dir + ".old"
+	if err := os.Rename(prefix, old); err != nil && !os.IsNotExist(err) {
+		return err
+	} else if err != nil {
+		return os.Rename(dir, prefix)
+	}
+	if err := os.Rename(dir, prefix); err != nil {
+		os.Rename(old, prefix)
+		return err
+	}
+	return os.RemoveAll(old)
+}
+
+// Written returns the paths of the files that the last Flush wrote.
+func (w *TempWriter) Written() []string {
+	return w.written
+}
+
+// Removed returns the paths of the files that the last Flush removed.
+func (w *TempWriter) Removed() []string {
+	return w.removed
+}
+
+// Stale returns the paths of the files in the output directory prefix that flushing the files
+// named generated (relative to prefix) would remove: the files listed in its manifest that are no
+// longer generated, and if wipe is set, all other files that are not generated. The paths are
+// sorted, and include prefix.
+func Stale(prefix string, generated []string, wipe bool) ([]string, error) {
+	keep := map[string]bool{filepath.Join(prefix, ManifestName): true}
+	for _, filename := range generated {
+		keep[filepath.Join(prefix, filename)] = true
+	}
+	stale := map[string]bool{}
+
+	owned, err := ReadManifest(prefix)
+	if err != nil {
+		return nil, err
+	}
+	for _, filename := range owned {
+		path := filepath.Join(prefix, filename)
+		if _, err := os.Stat(path); err == nil && !keep[path] {
+			stale[path] = true
+		}
+	}
+	if wipe {
+		err := filepath.Walk(prefix, func(path string, info os.FileInfo, err error) error {
+			if os.IsNotExist(err) {
+				return nil
+			} else if err != nil || info.IsDir() || keep[path] {
+				return err
+			}
+			stale[path] = true
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([]string, 0, len(stale))
+	for path := range stale {
+		out = append(out, path)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// ReadManifest returns the names of the files listed in the manifest of the output directory
+// prefix, relative to prefix. It returns nil if there is no manifest.
+func ReadManifest(prefix string) ([]string, error) {
+	f, err := os.Open(filepath.Join(prefix, ManifestName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		filename := filepath.FromSlash(line)
+		// Never remove files outside of the output directory, whatever the manifest says.
+		if filepath.IsAbs(filename) || strings.HasPrefix(filepath.Clean(filename), "..") {
+			continue
+		}
+		out = append(out, filepath.Clean(filename))
+	}
+	return out, scanner.Err()
+}
+
+// manifest returns the contents of a manifest that lists filenames, sorted and without duplicates.
+func manifest(filenames []string) []byte {
+	sorted := append([]string{}, filenames...)
+	sort.Strings(sorted)
+	out := &bytes.Buffer{}
+	out.WriteString(manifestHeader + "\n")
+	for idx, filename := range sorted {
+		if idx > 0 && filename == sorted[idx-1] {
+			continue
+		}
+		out.WriteString(filepath.ToSlash(filename) + "\n")
+	}
+	return out.Bytes()
+}
+
+// unchanged returns true if the file at path has contents.
+func unchanged(path string, contents []byte) bool {
+	existing, err := ioutil.ReadFile(path)
+	return err == nil && bytes.Equal(existing, contents)
+}
+
+// writeFile writes contents to path in the staged directory, creating its directory if necessary.
+// A file that is already at path is removed first rather than written to, since it may be linked to
+// a file of the output directory.
+func writeFile(path string, contents []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return ioutil.WriteFile(path, contents, 0644)
+}
+
+// removeEmptyDirs removes dir and its parents inside the output directory prefix while they are
+// empty.
+func removeEmptyDirs(prefix, dir string) {
+	for {
+		rel, err := filepath.Rel(prefix, dir)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") || os.Remove(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
 	}
-	return nil
 }
@@ -1,8 +1,147 @@
 package tempwriter_test
 
 import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
 	"github.com/visor-tax/firemodel"
 	"github.com/visor-tax/firemodel/internal/tempwriter"
+	"gotest.tools/assert"
 )
 
 var _ firemodel.SourceCoder = &tempwriter.TempWriter{}
+
+func TestFlush(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tempwriter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	out := filepath.Join(dir, "out")
+
+	flush := func(wipe bool, files map[string]string) *tempwriter.TempWriter {
+		w := tempwriter.New(out, wipe)
+		for filename, contents := range files {
+			f, err := w.NewFile(filename)
+			assert.NilError(t, err)
+			_, err = io.WriteString(f, contents)
+			assert.NilError(t, err)
+			assert.NilError(t, f.Close())
+		}
+		assert.NilError(t, w.Flush())
+		return w
+	}
+	read := func(filename string) string {
+		contents, err := ioutil.ReadFile(filepath.Join(out, filename))
+		assert.NilError(t, err)
+		return string(contents)
+	}
+	exists := func(filename string) bool {
+		_, err := os.Stat(filepath.Join(out, filename))
+		return err == nil
+	}
+
+	w := flush(false, map[string]string{"a.go": "a", "sub/b.go": "b"})
+	assert.DeepEqual(t, w.Written(), []string{filepath.Join(out, "a.go"), filepath.Join(out, "sub", "b.go")})
+	assert.Equal(t, read("sub/b.go"), "b")
+	assert.Equal(t, read(tempwriter.ManifestName), "# Files generated by firemodel. Do not edit.\na.go\nsub/b.go\n")
+
+	// Unchanged files aren't written, and files that are no longer generated are removed along
+	// with their directories, but files that weren't generated are kept.
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	assert.NilError(t, os.Chtimes(filepath.Join(out, "a.go"), old, old))
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(out, "README"), []byte("mine"), 0644))
+	w = flush(false, map[string]string{"a.go": "a", "c.go": "c"})
+	assert.DeepEqual(t, w.Written(), []string{filepath.Join(out, "c.go")})
+	assert.DeepEqual(t, w.Removed(), []string{filepath.Join(out, "sub", "b.go")})
+	info, err := os.Stat(filepath.Join(out, "a.go"))
+	assert.NilError(t, err)
+	assert.Assert(t, info.ModTime().Equal(old))
+	assert.Assert(t, !exists("sub"))
+	assert.Assert(t, exists("README"))
+
+	// Wiping removes the files that weren't generated too.
+	w = flush(true, map[string]string{"a.go": "a2"})
+	assert.DeepEqual(t, w.Written(), []string{filepath.Join(out, "a.go")})
+	assert.DeepEqual(t, w.Removed(), []string{filepath.Join(out, "README"), filepath.Join(out, "c.go")})
+	assert.Equal(t, read("a.go"), "a2")
+	assert.Equal(t, read(tempwriter.ManifestName), "# Files generated by firemodel. Do not edit.\na.go\n")
+
+	files, err := ioutil.ReadDir(out)
+	assert.NilError(t, err)
+	assert.Equal(t, len(files), 2)
+	files, err = ioutil.ReadDir(dir)
+	assert.NilError(t, err)
+	assert.Equal(t, len(files), 1, "staged directories are left over")
+}
+
+// TestFlushFailure checks that a Flush that fails leaves the output directory as it was.
+func TestFlushFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tempwriter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	out := filepath.Join(dir, "out")
+
+	flush := func(files map[string]string) (*tempwriter.TempWriter, error) {
+		w := tempwriter.New(out, false)
+		for filename, contents := range files {
+			f, err := w.NewFile(filename)
+			assert.NilError(t, err)
+			_, err = io.WriteString(f, contents)
+			assert.NilError(t, err)
+			assert.NilError(t, f.Close())
+		}
+		return w, w.Flush()
+	}
+	read := func(filename string) string {
+		contents, err := ioutil.ReadFile(filepath.Join(out, filename))
+		assert.NilError(t, err)
+		return string(contents)
+	}
+
+	_, err = flush(map[string]string{"a.go": "a", "old.go": "old"})
+	assert.NilError(t, err)
+
+	// b.go can't be written over a directory that wasn't generated.
+	assert.NilError(t, os.MkdirAll(filepath.Join(out, "b.go", "sub"), 0777))
+	w, err := flush(map[string]string{"a.go": "a2", "b.go": "b", "c.go": "c"})
+	assert.Assert(t, err != nil)
+	assert.Assert(t, w.Written() == nil)
+	assert.Equal(t, read("a.go"), "a")
+	assert.Equal(t, read("old.go"), "old")
+	assert.Equal(t, read(tempwriter.ManifestName), "# Files generated by firemodel. Do not edit.\na.go\nold.go\n")
+	files, err := ioutil.ReadDir(dir)
+	assert.NilError(t, err)
+	assert.Equal(t, len(files), 1, "staged directories are left over")
+
+	assert.NilError(t, os.RemoveAll(filepath.Join(out, "b.go")))
+	w, err = flush(map[string]string{"a.go": "a2", "b.go": "b", "c.go": "c"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, w.Written(), []string{filepath.Join(out, "a.go"), filepath.Join(out, "b.go"), filepath.Join(out, "c.go")})
+	assert.DeepEqual(t, w.Removed(), []string{filepath.Join(out, "old.go")})
+	assert.Equal(t, read(tempwriter.ManifestName), "# Files generated by firemodel. Do not edit.\na.go\nb.go\nc.go\n")
+}
+
+func TestReadManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tempwriter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files, err := tempwriter.ReadManifest(dir)
+	assert.NilError(t, err)
+	assert.Assert(t, files == nil)
+
+	manifest := "# comment\na.go\n\n../outside.go\n/etc/passwd\nsub/../b.go\n"
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(dir, tempwriter.ManifestName), []byte(manifest), 0644))
+	files, err = tempwriter.ReadManifest(dir)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, files, []string{"a.go", "b.go"})
+}
@@ -0,0 +1,208 @@
+package ir
+
+import (
+	"github.com/visor-tax/firemodel"
+)
+
+// FromSchema returns the representation of a compiled schema.
+func FromSchema(schema *firemodel.Schema) *Schema {
+	out := &Schema{
+		Version: Version,
+		Options: options(schema.Options),
+		Models:  []*Model{},
+		Structs: []*Struct{},
+		Enums:   []*Enum{},
+		Unions:  []*Union{},
+		Aliases: []*Alias{},
+	}
+	for _, model := range schema.Models {
+		m := &Model{
+			Name:     model.Name,
+			Package:  model.Package,
+			Comment:  model.Comment,
+			Extends:  structRefs(model.Extends),
+			Fields:   fields(model.Fields),
+			Reserved: model.Reserved,
+			Options:  options(firemodel.SchemaOptions(model.Options)),
+			Pos:      position(model.Pos),
+		}
+		for _, collection := range model.Collections {
+			m.Collections = append(m.Collections, &Collection{
+				Name:    collection.Name,
+				Comment: collection.Comment,
+				Model:   Ref{Package: collection.Type.Package, Name: collection.Type.Name},
+				Pos:     position(collection.Pos),
+			})
+		}
+		out.Models = append(out.Models, m)
+	}
+	for _, structType := range schema.Structs {
+		out.Structs = append(out.Structs, &Struct{
+			Name:     structType.Name,
+			Package:  structType.Package,
+			Comment:  structType.Comment,
+			Extends:  structRefs(structType.Extends),
+			Fields:   fields(structType.Fields),
+			Reserved: structType.Reserved,
+			Pos:      position(structType.Pos),
+		})
+	}
+	for _, enum := range schema.Enums {
+		e := &Enum{
+			Name:     enum.Name,
+			Package:  enum.Package,
+			Comment:  enum.Comment,
+			Backing:  enum.Backing.String(),
+			Values:   []*EnumValue{},
+			Reserved: enum.Reserved,
+			Pos:      position(enum.Pos),
+		}
+		for _, value := range enum.Values {
+			e.Values = append(e.Values, &EnumValue{
+				Name:       value.Name,
+				Comment:    value.Comment,
+				WireValue:  value.WireValue,
+				Aliases:    value.Aliases,
+				Deprecated: value.Deprecated,
+				Pos:        position(value.Pos),
+			})
+		}
+		out.Enums = append(out.Enums, e)
+	}
+	for _, union := range schema.Unions {
+		out.Unions = append(out.Unions, &Union{
+			Name:          union.Name,
+			Package:       union.Package,
+			Comment:       union.Comment,
+			Discriminator: union.Discriminator,
+			Members:       fields(union.Members),
+			Options:       options(firemodel.SchemaOptions(union.Options)),
+			Pos:           position(union.Pos),
+		})
+	}
+	for _, alias := range schema.Aliases {
+		out.Aliases = append(out.Aliases, &Alias{
+			Name:        alias.Name,
+			Package:     alias.Package,
+			Comment:     alias.Comment,
+			Type:        fieldType(alias.Type),
+			Constraints: constraints(alias.Constraints),
+			Options:     options(firemodel.SchemaOptions(alias.Options)),
+			Pos:         position(alias.Pos),
+		})
+	}
+	return out
+}
+
+func fields(in []*firemodel.SchemaField) []*Field {
+	out := []*Field{}
+	for _, field := range in {
+		f := &Field{
+			Name:        field.Name,
+			WireName:    field.WireName,
+			Comment:     field.Comment,
+			Type:        fieldType(field.Type),
+			Nullable:    field.Nullable,
+			Constraints: constraints(field.Constraints),
+			Options:     options(firemodel.SchemaOptions(field.Options)),
+			Deprecated:  field.Deprecated,
+			Pos:         position(field.Pos),
+		}
+		switch field.Presence {
+		case firemodel.PresenceRequired:
+			f.Presence = "required"
+		case firemodel.PresenceOptional:
+			f.Presence = "optional"
+		}
+		if value, ok := field.Default.(*firemodel.SchemaEnumValue); ok {
+			f.Default = value.Name
+		} else {
+			f.Default = field.Default
+		}
+		if field.Alias != nil {
+			f.Alias = &Ref{Package: field.Alias.Package, Name: field.Alias.Name}
+		}
+		if field.Origin != nil {
+			f.Origin = &Ref{Package: field.Origin.Package, Name: field.Origin.Name}
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+func fieldType(in firemodel.SchemaFieldType) *Type {
+	switch in := in.(type) {
+	case *firemodel.Boolean:
+		return &Type{Kind: Boolean}
+	case *firemodel.Integer:
+		return &Type{Kind: Integer}
+	case *firemodel.Double:
+		return &Type{Kind: Double}
+	case *firemodel.Timestamp:
+		return &Type{Kind: Timestamp}
+	case *firemodel.String:
+		return &Type{Kind: String}
+	case *firemodel.Bytes:
+		return &Type{Kind: Bytes}
+	case *firemodel.GeoPoint:
+		return &Type{Kind: GeoPoint}
+	case *firemodel.URL:
+		return &Type{Kind: URL}
+	case *firemodel.File:
+		return &Type{Kind: File}
+	case *firemodel.Reference:
+		out := &Type{Kind: Reference}
+		if in.T != nil {
+			out.Ref = &Ref{Package: in.T.Package, Name: in.T.Name}
+		}
+		return out
+	case *firemodel.Array:
+		return &Type{Kind: Array, Items: fieldType(in.T)}
+	case *firemodel.Map:
+		return &Type{Kind: Map, Key: fieldType(in.K), Items: fieldType(in.T)}
+	case *firemodel.Struct:
+		return &Type{Kind: StructRef, Ref: &Ref{Package: in.T.Package, Name: in.T.Name}}
+	case *firemodel.Enum:
+		return &Type{Kind: EnumRef, Ref: &Ref{Package: in.T.Package, Name: in.T.Name}}
+	case *firemodel.Union:
+		return &Type{Kind: UnionRef, Ref: &Ref{Package: in.T.Package, Name: in.T.Name}}
+	default:
+		return nil
+	}
+}
+
+func constraints(in []*firemodel.SchemaConstraint) (out []*Constraint) {
+	for _, constraint := range in {
+		out = append(out, &Constraint{
+			Kind:    string(constraint.Kind),
+			Limit:   constraint.Limit,
+			Pattern: constraint.Pattern,
+		})
+	}
+	return
+}
+
+func structRefs(in []*firemodel.SchemaStruct) (out []Ref) {
+	for _, structType := range in {
+		out = append(out, Ref{Package: structType.Package, Name: structType.Name})
+	}
+	return
+}
+
+func options(in firemodel.SchemaOptions) Options {
+	if len(in) == 0 {
+		return nil
+	}
+	out := Options{}
+	for namespace, values := range in {
+		out[namespace] = map[string]string{}
+		for key, value := range values {
+			out[namespace][key] = value
+		}
+	}
+	return out
+}
+
+func position(pos firemodel.Position) Position {
+	return Position{File: pos.Filename, Line: pos.Line, Column: pos.Column}
+}
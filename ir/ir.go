@@ -0,0 +1,225 @@
+// Package ir is a serializable representation of a compiled firemodel schema, for tools that
+// consume schemas without linking the parser, e.g. documentation generators.
+//
+// Declarations refer to each other by name instead of by pointer, so a Schema can be encoded as
+// JSON as is. The encoding is versioned: fields may be added within a version, but are only
+// removed or changed in meaning when Version is incremented.
+package ir
+
+import "fmt"
+
+// Version is the version of the representation, stored in Schema.Version.
+const Version = 1
+
+// Schema is a compiled schema.
+type Schema struct {
+	Version int `json:"version"`
+	// Options are the file options of the schema, keyed by namespace and name, e.g.
+	// {"go": {"package": "models"}}.
+	Options Options   `json:"options,omitempty"`
+	Models  []*Model  `json:"models"`
+	Structs []*Struct `json:"structs"`
+	Enums   []*Enum   `json:"enums"`
+	Unions  []*Union  `json:"unions"`
+	Aliases []*Alias  `json:"aliases"`
+}
+
+// Options are options keyed by namespace and name. Options set without a namespace are stored
+// under "".
+type Options map[string]map[string]string
+
+// Ref refers to a declaration by its package and name.
+type Ref struct {
+	Package string `json:"package,omitempty"`
+	Name    string `json:"name"`
+}
+
+// Position is a location in a schema source file. Lines and columns start at 1.
+type Position struct {
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// Model is a model, whose documents are stored in a collection.
+type Model struct {
+	Name    string `json:"name"`
+	Package string `json:"package,omitempty"`
+	Comment string `json:"comment,omitempty"`
+	// Extends are the structs in the extends clause of the model.
+	Extends []Ref `json:"extends,omitempty"`
+	// Fields are the fields of the model, starting with the fields inherited from Extends.
+	Fields      []*Field      `json:"fields"`
+	Collections []*Collection `json:"collections,omitempty"`
+	Reserved    []string      `json:"reserved,omitempty"`
+	Options     Options       `json:"options,omitempty"`
+	Pos         Position      `json:"pos"`
+}
+
+// Collection is a collection of documents nested in every document of a model.
+type Collection struct {
+	Name    string   `json:"name"`
+	Comment string   `json:"comment,omitempty"`
+	Model   Ref      `json:"model"`
+	Pos     Position `json:"pos"`
+}
+
+// Struct is a struct, which is stored inline in the fields that use it.
+type Struct struct {
+	Name    string `json:"name"`
+	Package string `json:"package,omitempty"`
+	Comment string `json:"comment,omitempty"`
+	Extends []Ref  `json:"extends,omitempty"`
+	// Fields are the fields of the struct, starting with the fields inherited from Extends.
+	Fields   []*Field `json:"fields"`
+	Reserved []string `json:"reserved,omitempty"`
+	Pos      Position `json:"pos"`
+}
+
+// Enum is an enum.
+type Enum struct {
+	Name    string `json:"name"`
+	Package string `json:"package,omitempty"`
+	Comment string `json:"comment,omitempty"`
+	// Backing is the type that values are stored as: "string" or "integer".
+	Backing string       `json:"backing"`
+	Values  []*EnumValue `json:"values"`
+	// Reserved are the names (strings) and stored values (strings or integers) of removed values.
+	Reserved []interface{} `json:"reserved,omitempty"`
+	Pos      Position      `json:"pos"`
+}
+
+// EnumValue is a value of an enum.
+type EnumValue struct {
+	Name    string `json:"name"`
+	Comment string `json:"comment,omitempty"`
+	// WireValue is the value stored in Firestore: a string, or an integer for integer enums.
+	WireValue interface{} `json:"wire_value"`
+	// Aliases are other stored values that decode to this value.
+	Aliases    []interface{} `json:"aliases,omitempty"`
+	Deprecated bool          `json:"deprecated,omitempty"`
+	Pos        Position      `json:"pos"`
+}
+
+// Union is a union of structs.
+type Union struct {
+	Name    string `json:"name"`
+	Package string `json:"package,omitempty"`
+	Comment string `json:"comment,omitempty"`
+	// Discriminator is the key that the name of the member is stored under.
+	Discriminator string `json:"discriminator"`
+	// Members are the members of the union, whose types are structs.
+	Members []*Field `json:"members"`
+	Options Options  `json:"options,omitempty"`
+	Pos     Position `json:"pos"`
+}
+
+// Alias is a name for a primitive type.
+type Alias struct {
+	Name        string        `json:"name"`
+	Package     string        `json:"package,omitempty"`
+	Comment     string        `json:"comment,omitempty"`
+	Type        *Type         `json:"type"`
+	Constraints []*Constraint `json:"constraints,omitempty"`
+	Options     Options       `json:"options,omitempty"`
+	Pos         Position      `json:"pos"`
+}
+
+// Field is a field of a model or struct, or a member of a union.
+type Field struct {
+	Name string `json:"name"`
+	// WireName is the key the field is stored under in Firestore.
+	WireName string `json:"wire_name"`
+	Comment  string `json:"comment,omitempty"`
+	Type     *Type  `json:"type"`
+	// Presence is "required" or "optional" for fields declared with a presence modifier, and ""
+	// otherwise.
+	Presence string `json:"presence,omitempty"`
+	Nullable bool   `json:"nullable,omitempty"`
+	// Default is the default value of the field: a boolean, number or string. The default of an
+	// enum field is the name of the value.
+	Default     interface{}   `json:"default,omitempty"`
+	Constraints []*Constraint `json:"constraints,omitempty"`
+	Options     Options       `json:"options,omitempty"`
+	Deprecated  bool          `json:"deprecated,omitempty"`
+	// Alias is the alias the field was declared with. Type is the type it aliases.
+	Alias *Ref `json:"alias,omitempty"`
+	// Origin is the extended struct the field was inherited from.
+	Origin *Ref     `json:"origin,omitempty"`
+	Pos    Position `json:"pos"`
+}
+
+// TypeKind is the kind of a Type.
+type TypeKind string
+
+const (
+	Boolean   TypeKind = "boolean"
+	Integer   TypeKind = "integer"
+	Double    TypeKind = "double"
+	Timestamp TypeKind = "timestamp"
+	String    TypeKind = "string"
+	Bytes     TypeKind = "bytes"
+	Reference TypeKind = "reference"
+	GeoPoint  TypeKind = "geopoint"
+	Array     TypeKind = "array"
+	Map       TypeKind = "map"
+	URL       TypeKind = "url"
+	File      TypeKind = "file"
+	StructRef TypeKind = "struct"
+	EnumRef   TypeKind = "enum"
+	UnionRef  TypeKind = "union"
+)
+
+// Type is the type of a field.
+type Type struct {
+	Kind TypeKind `json:"kind"`
+	// Ref is the struct, enum or union of the type, or the model of a reference, which is nil for
+	// references to any document.
+	Ref *Ref `json:"ref,omitempty"`
+	// Key is the type of the keys of a map, or nil for string keys.
+	Key *Type `json:"key,omitempty"`
+	// Items is the type of the items of an array or the values of a map, or nil if they may have
+	// any type.
+	Items *Type `json:"items,omitempty"`
+}
+
+// Constraint is a validation constraint on the value of a field.
+type Constraint struct {
+	// Kind is the option the constraint is set with, e.g. "min" or "pattern".
+	Kind string `json:"kind"`
+	// Limit is the bound of min, max, length and items constraints. It is omitted when it is 0.
+	Limit float64 `json:"limit,omitempty"`
+	// Pattern is the regular expression of pattern constraints.
+	Pattern string `json:"pattern,omitempty"`
+}
+
+// String returns the type as it is written in a schema, e.g. "map<Emotion, integer>".
+func (t *Type) String() string {
+	switch t.Kind {
+	case StructRef, EnumRef, UnionRef:
+		return t.Ref.String()
+	case Reference:
+		if t.Ref != nil {
+			return fmt.Sprintf("reference<%s>", t.Ref)
+		}
+	case Array:
+		if t.Items != nil {
+			return fmt.Sprintf("array<%s>", t.Items)
+		}
+	case Map:
+		if t.Key != nil {
+			return fmt.Sprintf("map<%s, %s>", t.Key, t.Items)
+		} else if t.Items != nil {
+			return fmt.Sprintf("map<%s>", t.Items)
+		}
+	}
+	return string(t.Kind)
+}
+
+// String returns the name of the declaration, qualified by its package if it has one.
+func (r Ref) String() string {
+	if r.Package == "" {
+		return r.Name
+	}
+	return r.Package + "." + r.Name
+}
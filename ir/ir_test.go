@@ -0,0 +1,56 @@
+package ir_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/visor-tax/firemodel"
+	"github.com/visor-tax/firemodel/ir"
+	"gotest.tools/assert"
+)
+
+const fixturesRoot = "../testfixtures/ir"
+
+func TestFromSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		schemas []string
+	}{
+		{"example", []string{"../example/firemodel.example.firemodel"}},
+		{"packages", []string{"../example/packages/app.firemodel"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := firemodel.ParseSchemaFiles(tt.schemas...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := json.MarshalIndent(ir.FromSchema(schema), "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+
+			fixture := path.Join(fixturesRoot, tt.name+".json")
+			if _, update := os.LookupEnv("FIREMODEL_UPDATE_FIXTURES"); update {
+				if err := ioutil.WriteFile(fixture, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := ioutil.ReadFile(fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, string(got), string(want), "If this diff looks ok, re-run tests with FIREMODEL_UPDATE_FIXTURES=true")
+
+			// The fixture decodes to the same representation.
+			var decoded ir.Schema
+			assert.NilError(t, json.Unmarshal(want, &decoded))
+			assert.Equal(t, decoded.Version, ir.Version)
+		})
+	}
+}
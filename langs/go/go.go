@@ -2,7 +2,9 @@ package golang
 
 import (
 	"fmt"
+	"path"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/dave/jennifer/jen"
@@ -29,7 +31,10 @@ var (
 )
 
 type GoModeler struct {
+	schema      *firemodel.Schema
 	pkg         string
+	importPath  string
+	currentPkg  string
 	clientNames []*ClientName
 }
 
@@ -39,7 +44,33 @@ type ClientName struct {
 }
 
 func (m *GoModeler) Model(schema *firemodel.Schema, sourceCoder firemodel.SourceCoder) error {
+	m.schema = schema
 	m.pkg = schema.Options.Get("go")["package"]
+	m.importPath = schema.Options.Get("go")["import_path"]
+
+	// The default package is always generated, even if it is empty. Every other schema package is
+	// generated as a go package in a subdirectory of the output directory.
+	packages := []string{""}
+	for _, pkg := range schema.PackageNames() {
+		if pkg == "" {
+			continue
+		}
+		if m.importPath == "" {
+			return errors.Errorf("firemodel/go: option go.import_path is required to generate package %s", pkg)
+		}
+		packages = append(packages, pkg)
+	}
+
+	for _, pkg := range packages {
+		if err := m.writePackage(schema.InPackage(pkg), pkg, sourceCoder); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *GoModeler) writePackage(schema *firemodel.Schema, pkg string, sourceCoder firemodel.SourceCoder) error {
+	m.currentPkg = pkg
 	m.clientNames = []*ClientName{}
 	for _, model := range schema.Models {
 		if err := m.writeModel(model, sourceCoder); err != nil {
@@ -56,6 +87,16 @@ func (m *GoModeler) Model(schema *firemodel.Schema, sourceCoder firemodel.Source
 			return err
 		}
 	}
+	for _, union := range schema.Unions {
+		if err := m.writeUnion(union, sourceCoder); err != nil {
+			return err
+		}
+	}
+	for _, alias := range schema.Aliases {
+		if err := m.writeAlias(alias, sourceCoder); err != nil {
+			return err
+		}
+	}
 
 	if err := m.writeManifest(sourceCoder); err != nil {
 		return err
@@ -64,10 +105,23 @@ func (m *GoModeler) Model(schema *firemodel.Schema, sourceCoder firemodel.Source
 	return nil
 }
 
-func (m *GoModeler) writeManifest(sourceCoder firemodel.SourceCoder) error {
+func (m *GoModeler) newFile() *jen.File {
 	f := jen.NewFile(m.packageName())
 	f.ImportNames(importNames)
+	if m.importPath != "" {
+		f.ImportName(m.importPath, m.rootPackageName())
+	}
 	f.HeaderComment(fmt.Sprintf("DO NOT EDIT - Code generated by firemodel %s.", version.Version))
+	return f
+}
+
+// filename returns the name of a generated file, relative to the output directory.
+func (m *GoModeler) filename(name string) string {
+	return path.Join(m.currentPkg, name)
+}
+
+func (m *GoModeler) writeManifest(sourceCoder firemodel.SourceCoder) error {
+	f := m.newFile()
 
 	f.Type().Id("Client").StructFunc(func(g *jen.Group) {
 		g.Id("Client").Op("*").Qual("cloud.google.com/go/firestore", "Client")
@@ -89,7 +143,7 @@ func (m *GoModeler) writeManifest(sourceCoder firemodel.SourceCoder) error {
 		g.Return(jen.Id("temp"))
 	})
 
-	w, err := sourceCoder.NewFile("module.go")
+	w, err := sourceCoder.NewFile(m.filename("module.go"))
 	if err != nil {
 		return errors.Wrap(err, "firemodel/go: open source code file")
 	}
@@ -102,9 +156,7 @@ func (m *GoModeler) writeManifest(sourceCoder firemodel.SourceCoder) error {
 }
 
 func (m *GoModeler) writeModel(model *firemodel.SchemaModel, sourceCoder firemodel.SourceCoder) error {
-	f := jen.NewFile(m.packageName())
-	f.ImportNames(importNames)
-	f.HeaderComment(fmt.Sprintf("DO NOT EDIT - Code generated by firemodel %s.", version.Version))
+	f := m.newFile()
 
 	if model.Comment != "" {
 		f.Comment(model.Comment)
@@ -127,9 +179,11 @@ func (m *GoModeler) writeModel(model *firemodel.SchemaModel, sourceCoder firemod
 		Type().
 		Id(model.Name).
 		StructFunc(func(g *jen.Group) {
-			m.fields(model.Name, model.Fields, model.Options.GetAutoTimestamp())(g)
+			m.fields(model.Name, model.Extends, model.Fields, model.Options.GetAutoTimestamp())(g)
 
 		})
+	m.constructor(f, model.Name, model.Extends, model.Fields)
+	m.validate(f, model.Name, model.Extends, model.Fields)
 
 	if format, args, err := model.Options.GetFirestorePath(); format != "" {
 		f.
@@ -339,7 +393,7 @@ func (m *GoModeler) writeModel(model *firemodel.SchemaModel, sourceCoder firemod
 
 	}
 
-	w, err := sourceCoder.NewFile(fmt.Sprint(strcase.ToSnake(model.Name), fileExtension))
+	w, err := sourceCoder.NewFile(m.filename(fmt.Sprint(strcase.ToSnake(model.Name), fileExtension)))
 	if err != nil {
 		return errors.Wrap(err, "firemodel/go: open source code file")
 	}
@@ -353,26 +407,34 @@ func (m *GoModeler) writeModel(model *firemodel.SchemaModel, sourceCoder firemod
 
 func (m *GoModeler) writeEnum(enum *firemodel.SchemaEnum, sourceCoder firemodel.SourceCoder) error {
 	enumName := strcase.ToCamel(enum.Name)
-	f := jen.NewFile(m.packageName())
-	f.ImportNames(importNames)
-	f.HeaderComment(fmt.Sprintf("DO NOT EDIT - Code generated by firemodel %s.", version.Version))
+	f := m.newFile()
 
 	if enum.Comment != "" {
 		f.Comment(enum.Comment)
 	}
-	f.Type().Id(enumName).String()
+	if enum.Backing == firemodel.EnumBackingInteger {
+		f.Type().Id(enumName).Int64()
+	} else {
+		f.Type().Id(enumName).String()
+	}
 
 	f.Const().DefsFunc(func(g *jen.Group) {
 		for _, val := range enum.Values {
 			enumValName := fmt.Sprintf("%s_%s", enumName, strcase.ToScreamingSnake(val.Name))
-			if enum.Comment != "" {
+			if val.Comment != "" {
 				g.Comment(val.Comment)
 			}
+			if val.Deprecated {
+				if val.Comment != "" {
+					g.Comment("")
+				}
+				g.Commentf("Deprecated: %s is only kept to read existing documents.", enumValName)
+			}
 			g.
 				Id(enumValName).
 				Id(enumName).
 				Op("=").
-				Lit(strcase.ToScreamingSnake(val.Name))
+				Add(goLiteral(val.WireValue))
 		}
 	})
 	f.Var().Id(enumName + "_Strings").Op("=").Map(jen.Id(enumName)).String().ValuesFunc(func(g *jen.Group) {
@@ -388,7 +450,26 @@ func (m *GoModeler) writeEnum(enum *firemodel.SchemaEnum, sourceCoder firemodel.
 		}
 	})
 	f.Func().Params(jen.Id("e").Id(enumName)).Id("String").Params().String().Block(jen.Return(jen.Id(enumName + "_Strings").Index(jen.Id("e"))))
-	w, err := sourceCoder.NewFile(fmt.Sprint(strcase.ToSnake(enum.Name), fileExtension))
+	if hasAliases(enum) {
+		f.Commentf("%s_Aliases maps other values that a %s may be stored as to their current value.", enumName, enumName)
+		f.Var().Id(enumName + "_Aliases").Op("=").Map(jen.Id(enumName)).Id(enumName).ValuesFunc(func(g *jen.Group) {
+			for _, val := range enum.Values {
+				enumValName := fmt.Sprintf("%s_%s", enumName, strcase.ToScreamingSnake(val.Name))
+				for _, alias := range val.Aliases {
+					g.Add(goLiteral(alias)).Op(":").Id(enumValName)
+				}
+			}
+		})
+		f.Comment("Canonical returns the current value of e, which may have been stored as an alias.")
+		f.Func().Params(jen.Id("e").Id(enumName)).Id("Canonical").Params().Id(enumName).Block(
+			jen.If(
+				jen.List(jen.Id("value"), jen.Id("ok")).Op(":=").Id(enumName+"_Aliases").Index(jen.Id("e")),
+				jen.Id("ok"),
+			).Block(jen.Return(jen.Id("value"))),
+			jen.Return(jen.Id("e")),
+		)
+	}
+	w, err := sourceCoder.NewFile(m.filename(fmt.Sprint(strcase.ToSnake(enum.Name), fileExtension)))
 	if err != nil {
 		return errors.Wrap(err, "firemodel/go: open source code file")
 	}
@@ -403,16 +484,124 @@ func (m *GoModeler) writeEnum(enum *firemodel.SchemaEnum, sourceCoder firemodel.
 
 func (m *GoModeler) writeStruct(structType *firemodel.SchemaStruct, sourceCoder firemodel.SourceCoder) error {
 	structName := strcase.ToCamel(structType.Name)
-	f := jen.NewFile(m.packageName())
-	f.ImportNames(importNames)
-	f.HeaderComment(fmt.Sprintf("DO NOT EDIT - Code generated by firemodel %s.", version.Version))
+	f := m.newFile()
 
 	if structType.Comment != "" {
 		f.Comment(structType.Comment)
 	}
-	f.Type().Id(structName).StructFunc(m.fields(structName, structType.Fields, false))
+	f.Type().Id(structName).StructFunc(m.fields(structName, structType.Extends, structType.Fields, false))
+	m.constructor(f, structName, structType.Extends, structType.Fields)
+	m.validate(f, structName, structType.Extends, structType.Fields)
+
+	w, err := sourceCoder.NewFile(m.filename(fmt.Sprint(strcase.ToSnake(structType.Name), fileExtension)))
+	if err != nil {
+		return errors.Wrap(err, "firemodel/go: open source code file")
+	}
+
+	defer w.Close()
+
+	if err := f.Render(w); err != nil {
+		return err
+	}
+	return nil
+}
+
+// writeUnion declares a union as a struct with the discriminator and a pointer field for every
+// member, which is how it is stored, along with an interface that is implemented by its members.
+func (m *GoModeler) writeUnion(union *firemodel.SchemaUnion, sourceCoder firemodel.SourceCoder) error {
+	unionName := strcase.ToCamel(union.Name)
+	memberName := unionName + "Member"
+	marker := "is" + memberName
+	f := m.newFile()
+
+	if union.Comment != "" {
+		f.Comment(union.Comment)
+		f.Comment("")
+	}
+	f.Commentf("A %s is stored with the name of its member under %q, and the member under that name.", unionName, union.Discriminator)
+	f.Commentf("Use Value and Set to access the member.")
+	f.Type().Id(unionName).StructFunc(func(g *jen.Group) {
+		g.Id(strcase.ToCamel(union.Discriminator)).String().Tag(map[string]string{"firestore": union.Discriminator})
+		for _, member := range union.Members {
+			if member.Comment != "" {
+				g.Comment(member.Comment)
+			}
+			g.Id(strcase.ToCamel(member.Name)).Do(m.goType(member.Type)).Tag(map[string]string{"firestore": member.WireName + ",omitempty"})
+		}
+	})
+
+	f.Commentf("%s is implemented by the members of %s.", memberName, unionName)
+	f.Type().Id(memberName).Interface(jen.Id(marker).Params())
+	for _, member := range union.Members {
+		f.Func().Params(jen.Op("*").Id(strcase.ToCamel(member.Type.(*firemodel.Struct).T.Name))).Id(marker).Params().Block()
+	}
+
+	discriminator := jen.Id("u").Dot(strcase.ToCamel(union.Discriminator))
+	f.Commentf("New%s returns a %s that holds member.", unionName, unionName)
+	f.Func().Id("New"+unionName).Params(jen.Id("member").Id(memberName)).Op("*").Id(unionName).Block(
+		jen.Id("u").Op(":=").Op("&").Id(unionName).Values(),
+		jen.Id("u").Dot("Set").Call(jen.Id("member")),
+		jen.Return(jen.Id("u")),
+	)
+	f.Commentf("Value returns the member that the %s holds, or nil if it holds none.", unionName)
+	f.Func().Params(jen.Id("u").Op("*").Id(unionName)).Id("Value").Params().Id(memberName).Block(
+		jen.Switch(discriminator.Clone()).BlockFunc(func(g *jen.Group) {
+			for _, member := range union.Members {
+				value := jen.Id("u").Dot(strcase.ToCamel(member.Name))
+				g.Case(jen.Lit(member.WireName)).Block(
+					jen.If(value.Clone().Op("!=").Nil()).Block(jen.Return(value.Clone())),
+				)
+			}
+		}),
+		jen.Return(jen.Nil()),
+	)
+	f.Commentf("Set replaces the member that the %s holds with member.", unionName)
+	f.Func().Params(jen.Id("u").Op("*").Id(unionName)).Id("Set").Params(jen.Id("member").Id(memberName)).Block(
+		jen.Op("*").Id("u").Op("=").Id(unionName).Values(),
+		jen.Switch(jen.Id("member").Op(":=").Id("member").Assert(jen.Type())).BlockFunc(func(g *jen.Group) {
+			for _, member := range union.Members {
+				g.Case(jen.Do(m.goType(member.Type))).Block(
+					discriminator.Clone().Op("=").Lit(member.WireName),
+					jen.Id("u").Dot(strcase.ToCamel(member.Name)).Op("=").Id("member"),
+				)
+			}
+		}),
+	)
+
+	w, err := sourceCoder.NewFile(m.filename(fmt.Sprint(strcase.ToSnake(union.Name), fileExtension)))
+	if err != nil {
+		return errors.Wrap(err, "firemodel/go: open source code file")
+	}
+
+	defer w.Close()
+
+	if err := f.Render(w); err != nil {
+		return err
+	}
+	return nil
+}
+
+// writeAlias declares a named type for an alias. Types that the firestore package only encodes as
+// themselves, and runtime.URL, which has methods, are declared as go aliases instead.
+func (m *GoModeler) writeAlias(alias *firemodel.SchemaAlias, sourceCoder firemodel.SourceCoder) error {
+	aliasName := strcase.ToCamel(alias.Name)
+	f := m.newFile()
+
+	if alias.Comment != "" {
+		f.Comment(alias.Comment)
+	}
+	switch alias.Type.(type) {
+	case *firemodel.Timestamp,
+		*firemodel.Bytes,
+		*firemodel.GeoPoint,
+		*firemodel.Reference,
+		*firemodel.URL:
+		f.Type().Id(aliasName).Op("=").Do(m.goType(alias.Type))
+	default:
+		f.Type().Id(aliasName).Do(m.goType(alias.Type))
+	}
 
-	w, err := sourceCoder.NewFile(fmt.Sprint(strcase.ToSnake(structType.Name), fileExtension))
+	w, err := sourceCoder.NewFile(m.filename(fmt.Sprint(strcase.ToSnake(alias.Name), fileExtension)))
 	if err != nil {
 		return errors.Wrap(err, "firemodel/go: open source code file")
 	}
@@ -426,36 +615,135 @@ func (m *GoModeler) writeStruct(structType *firemodel.SchemaStruct, sourceCoder
 }
 
 func (m *GoModeler) packageName() string {
+	if m.currentPkg != "" {
+		return m.currentPkg
+	}
+	return m.rootPackageName()
+}
+
+func (m *GoModeler) rootPackageName() string {
 	if m.pkg == "" {
 		return "firemodel"
 	}
 	return m.pkg
 }
 
+// typeName returns a reference to a generated type, qualified with its go package when it is
+// declared in a different schema package than the file being generated.
+func (m *GoModeler) typeName(s *jen.Statement, pkg string, name string) {
+	if pkg == m.currentPkg {
+		s.Id(name)
+	} else if pkg == "" {
+		s.Qual(m.importPath, name)
+	} else {
+		s.Qual(path.Join(m.importPath, pkg), name)
+	}
+}
+
 func (m *GoModeler) fieldTags(field *firemodel.SchemaField) string {
-	switch field.Type.(type) {
+	if m.omitEmpty(field) {
+		return field.WireName + ",omitempty"
+	}
+	return field.WireName
+}
+
+// omitEmpty returns true if the zero value of field is left out of documents. Optional fields are
+// always omitted when empty, and required and nullable fields are always written.
+func (m *GoModeler) omitEmpty(field *firemodel.SchemaField) bool {
+	switch {
+	case field.IsOptional():
+		return true
+	case field.IsRequired(), field.Nullable:
+		return false
+	}
+	switch firetype := field.Type.(type) {
 	// "false" and "0" should be written
 	case *firemodel.Boolean,
 		*firemodel.Integer,
 		*firemodel.Double:
-		return strcase.ToLowerCamel(field.Name)
+		return false
+	case *firemodel.Enum:
+		return firetype.T.Backing != firemodel.EnumBackingInteger
 
 	default:
-		return strcase.ToLowerCamel(field.Name) + ",omitempty"
+		return true
+	}
+}
+
+// fieldType returns the go type of field. Optional and nullable values are pointers, so that a
+// missing or null value can be told apart from the zero value, and required structs are values.
+func (m *GoModeler) fieldType(field *firemodel.SchemaField) func(s *jen.Statement) {
+	if alias := field.Alias; alias != nil {
+		if isPointer(field) {
+			return func(s *jen.Statement) { m.typeName(s.Op("*"), alias.Package, alias.Name) }
+		}
+		return func(s *jen.Statement) { m.typeName(s, alias.Package, alias.Name) }
+	}
+	switch firetype := field.Type.(type) {
+	case *firemodel.Struct:
+		if !isPointer(field) {
+			return func(s *jen.Statement) { m.typeName(s, firetype.T.Package, firetype.T.Name) }
+		}
+	case *firemodel.Union:
+		if !isPointer(field) {
+			return func(s *jen.Statement) { m.typeName(s, firetype.T.Package, firetype.T.Name) }
+		}
+	case *firemodel.Boolean,
+		*firemodel.Integer,
+		*firemodel.Double,
+		*firemodel.Timestamp,
+		*firemodel.String,
+		*firemodel.URL,
+		*firemodel.Enum:
+		if isPointer(field) {
+			return func(s *jen.Statement) { s.Op("*").Do(m.goType(field.Type)) }
+		}
 	}
+	return m.goType(field.Type)
+}
 
+// isPointer returns true if field is declared as a pointer to its type.
+func isPointer(field *firemodel.SchemaField) bool {
+	switch field.Type.(type) {
+	case *firemodel.Struct, *firemodel.Union:
+		return !field.IsRequired() || field.Nullable
+	case *firemodel.Boolean,
+		*firemodel.Integer,
+		*firemodel.Double,
+		*firemodel.Timestamp,
+		*firemodel.String,
+		*firemodel.URL,
+		*firemodel.Enum:
+		return field.IsOptional() || field.Nullable
+	default:
+		return false
+	}
 }
 
-func (m *GoModeler) fields(structName string, fields []*firemodel.SchemaField, addTimestampFields bool) func(g *jen.Group) {
+// fields declares the fields of a struct. The structs it extends are embedded, so that their
+// fields are stored inline, and the fields inherited from them are left out.
+func (m *GoModeler) fields(structName string, extends []*firemodel.SchemaStruct, fields []*firemodel.SchemaField, addTimestampFields bool) func(g *jen.Group) {
 	return func(g *jen.Group) {
+		for _, parent := range extends {
+			g.Do(func(s *jen.Statement) { m.typeName(s, parent.Package, parent.Name) })
+		}
 		for _, field := range fields {
+			if field.Origin != nil {
+				continue
+			}
 			if field.Comment != "" {
 				g.Comment(field.Comment)
 			}
+			if field.Deprecated {
+				if field.Comment != "" {
+					g.Comment("")
+				}
+				g.Comment("Deprecated: this field should no longer be used.")
+			}
 
 			g.
 				Id(strcase.ToCamel(field.Name)).
-				Do(m.goType(field.Type)).
+				Do(m.fieldType(field)).
 				Tag(map[string]string{"firestore": m.fieldTags(field)})
 		}
 		if addTimestampFields {
@@ -475,6 +763,207 @@ func (m *GoModeler) fields(structName string, fields []*firemodel.SchemaField, a
 	}
 }
 
+// constructor declares a New<name> function that returns a new name initialized with the default
+// values of its fields. Nothing is declared if no field has a default value.
+func (m *GoModeler) constructor(f *jen.File, name string, extends []*firemodel.SchemaStruct, fields []*firemodel.SchemaField) {
+	values := jen.Dict{}
+	for _, parent := range extends {
+		if structType := m.schema.Struct(parent.Package, parent.Name); structType != nil && hasDefaults(structType.Fields) {
+			values[jen.Id(parent.Name)] = jen.Op("*").Do(func(s *jen.Statement) { m.typeName(s, parent.Package, "New"+parent.Name) }).Call()
+		}
+	}
+	for _, field := range fields {
+		if field.Default != nil && field.Origin == nil {
+			values[jen.Id(strcase.ToCamel(field.Name))] = m.goValue(field)
+		}
+	}
+	if len(values) == 0 {
+		return
+	}
+
+	f.Commentf("New%s returns a new %s with default values.", name, name)
+	f.Func().Id("New" + name).Params().Op("*").Id(name).Block(
+		jen.Return(jen.Op("&").Id(name).Values(values)),
+	)
+}
+
+// hasDefaults returns true if any of fields has a default value.
+func hasDefaults(fields []*firemodel.SchemaField) bool {
+	for _, field := range fields {
+		if field.Default != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *GoModeler) goValue(field *firemodel.SchemaField) *jen.Statement {
+	switch value := field.Default.(type) {
+	case *firemodel.SchemaEnumValue:
+		enum := field.Type.(*firemodel.Enum).T
+		return jen.Do(func(s *jen.Statement) {
+			m.typeName(s, enum.Package, fmt.Sprintf("%s_%s", strcase.ToCamel(enum.Name), strcase.ToScreamingSnake(value.Name)))
+		})
+	default:
+		return goLiteral(value)
+	}
+}
+
+// validate declares a Validate method that checks the validation constraints of fields, including
+// the fields of the structs they contain. Nothing is declared if there is nothing to validate.
+func (m *GoModeler) validate(f *jen.File, name string, extends []*firemodel.SchemaStruct, fields []*firemodel.SchemaField) {
+	if !m.schema.HasValidation(fields) {
+		return
+	}
+
+	for _, field := range fields {
+		if field.Origin != nil {
+			continue
+		}
+		for _, constraint := range field.Constraints {
+			if constraint.Kind == firemodel.ConstraintPattern {
+				f.Var().Id(patternName(name, field, constraint)).Op("=").Qual("regexp", "MustCompile").Call(jen.Lit(constraint.Pattern))
+			}
+		}
+	}
+
+	f.Commentf("Validate returns an error if the %s violates a validation constraint of the schema.", name)
+	f.Func().Params(jen.Id("m").Op("*").Id(name)).Id("Validate").Params().Error().BlockFunc(func(g *jen.Group) {
+		for _, parent := range extends {
+			if structType := m.schema.Struct(parent.Package, parent.Name); structType != nil && m.schema.HasValidation(structType.Fields) {
+				g.If(
+					jen.Err().Op(":=").Id("m").Dot(parent.Name).Dot("Validate").Call(),
+					jen.Err().Op("!=").Nil(),
+				).Block(jen.Return(jen.Err()))
+			}
+		}
+		for _, field := range fields {
+			if field.Origin == nil {
+				m.validateField(g, name, field)
+			}
+		}
+		g.Return(jen.Nil())
+	})
+}
+
+func (m *GoModeler) validateField(g *jen.Group, name string, field *firemodel.SchemaField) {
+	value := jen.Id("m").Dot(strcase.ToCamel(field.Name))
+	_, isStruct := field.Type.(*firemodel.Struct)
+	pointer := isPointer(field)
+
+	var checks []jen.Code
+	for _, constraint := range field.Constraints {
+		v := value.Clone()
+		if pointer {
+			v = jen.Op("*").Add(v)
+		}
+		if _, ok := field.Type.(*firemodel.String); ok && field.Alias != nil {
+			v = jen.String().Call(v)
+		}
+		var violated *jen.Statement
+		switch constraint.Kind {
+		case firemodel.ConstraintMin:
+			violated = v.Op("<").Add(goLimit(field, constraint))
+		case firemodel.ConstraintMax:
+			violated = v.Op(">").Add(goLimit(field, constraint))
+		case firemodel.ConstraintMinLength:
+			violated = jen.Qual("unicode/utf8", "RuneCountInString").Call(v).Op("<").Add(goLimit(field, constraint))
+		case firemodel.ConstraintMaxLength:
+			violated = jen.Qual("unicode/utf8", "RuneCountInString").Call(v).Op(">").Add(goLimit(field, constraint))
+		case firemodel.ConstraintPattern:
+			violated = jen.Op("!").Id(patternName(name, field, constraint)).Dot("MatchString").Call(v)
+		case firemodel.ConstraintNonEmpty:
+			violated = jen.Len(v).Op("==").Lit(0)
+		case firemodel.ConstraintMinItems:
+			violated = jen.Len(v).Op("<").Add(goLimit(field, constraint))
+		case firemodel.ConstraintMaxItems:
+			violated = jen.Len(v).Op(">").Add(goLimit(field, constraint))
+		default:
+			continue
+		}
+		message := fmt.Sprintf("%s: %s", field.WireName, constraint.Message())
+		checks = append(checks, jen.If(violated).Block(jen.Return(jen.Qual("errors", "New").Call(jen.Lit(message)))))
+	}
+
+	if structType := m.schema.NestedStruct(field.Type); structType != nil && m.schema.HasValidation(structType.Fields) {
+		if isStruct {
+			checks = append(checks, jen.If(
+				jen.Err().Op(":=").Add(value.Clone()).Dot("Validate").Call(),
+				jen.Err().Op("!=").Nil(),
+			).Block(
+				jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit(field.WireName+".%s"), jen.Err())),
+			))
+		} else {
+			checks = append(checks, jen.For(jen.List(jen.Id("i"), jen.Id("item")).Op(":=").Range().Add(value.Clone())).Block(
+				jen.If(jen.Id("item").Op("==").Nil()).Block(jen.Continue()),
+				jen.If(
+					jen.Err().Op(":=").Id("item").Dot("Validate").Call(),
+					jen.Err().Op("!=").Nil(),
+				).Block(
+					jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit(field.WireName+"[%d].%s"), jen.Id("i"), jen.Err())),
+				),
+			))
+		}
+	}
+
+	if len(checks) == 0 {
+		return
+	}
+	// Optional and nullable fields are only validated when they are set, whatever their type.
+	if pointer || field.IsOptional() || field.Nullable {
+		g.If(value.Clone().Op("!=").Nil()).Block(checks...)
+	} else {
+		for _, check := range checks {
+			g.Add(check)
+		}
+	}
+}
+
+// patternName returns the name of the variable that holds the compiled pattern of constraint, a
+// constraint of field. A field has several patterns when it sets one and so does its alias, in which
+// case the ones after the first are numbered.
+func patternName(name string, field *firemodel.SchemaField, constraint *firemodel.SchemaConstraint) string {
+	n := 0
+	for _, other := range field.Constraints {
+		if other.Kind == firemodel.ConstraintPattern {
+			n++
+		}
+		if other == constraint {
+			break
+		}
+	}
+	out := strcase.ToLowerCamel(name) + strcase.ToCamel(field.Name) + "Pattern"
+	if n > 1 {
+		out += strconv.Itoa(n)
+	}
+	return out
+}
+
+func goLimit(field *firemodel.SchemaField, constraint *firemodel.SchemaConstraint) *jen.Statement {
+	if _, ok := field.Type.(*firemodel.Double); ok {
+		return jen.Lit(constraint.Limit)
+	}
+	return jen.Lit(int(constraint.Limit))
+}
+
+// goLiteral returns a literal for value, a string or an int64, that can be assigned to any type
+// with the same underlying type.
+func goLiteral(value interface{}) *jen.Statement {
+	if i, ok := value.(int64); ok {
+		return jen.Lit(int(i))
+	}
+	return jen.Lit(value)
+}
+
+func hasAliases(enum *firemodel.SchemaEnum) bool {
+	for _, val := range enum.Values {
+		if len(val.Aliases) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 func (m *GoModeler) goType(firetype firemodel.SchemaFieldType) func(s *jen.Statement) {
 	switch firetype := firetype.(type) {
 	case *firemodel.Boolean:
@@ -490,7 +979,7 @@ func (m *GoModeler) goType(firetype firemodel.SchemaFieldType) func(s *jen.State
 	case *firemodel.URL:
 		return func(s *jen.Statement) { s.Qual("github.com/visor-tax/firemodel/runtime", "URL") }
 	case *firemodel.Enum:
-		return func(s *jen.Statement) { s.Id(firetype.T.Name) }
+		return func(s *jen.Statement) { m.typeName(s, firetype.T.Package, firetype.T.Name) }
 	case *firemodel.Bytes:
 		return func(s *jen.Statement) { s.Index().Byte() }
 	case *firemodel.Reference:
@@ -498,7 +987,9 @@ func (m *GoModeler) goType(firetype firemodel.SchemaFieldType) func(s *jen.State
 	case *firemodel.GeoPoint:
 		return func(s *jen.Statement) { s.Op("*").Qual("google.golang.org/genproto/googleapis/type/latlng", "LatLng") }
 	case *firemodel.Struct:
-		return func(s *jen.Statement) { s.Op("*").Id(firetype.T.Name) }
+		return func(s *jen.Statement) { m.typeName(s.Op("*"), firetype.T.Package, firetype.T.Name) }
+	case *firemodel.Union:
+		return func(s *jen.Statement) { m.typeName(s.Op("*"), firetype.T.Package, firetype.T.Name) }
 	case *firemodel.Array:
 		if firetype.T != nil {
 			return func(s *jen.Statement) { s.Index().Do(m.goType(firetype.T)) }
@@ -507,6 +998,9 @@ func (m *GoModeler) goType(firetype firemodel.SchemaFieldType) func(s *jen.State
 	case *firemodel.File:
 		return func(s *jen.Statement) { s.Op("*").Qual("github.com/visor-tax/firemodel/runtime", "File") }
 	case *firemodel.Map:
+		if firetype.K != nil {
+			return func(s *jen.Statement) { s.Map(jen.Do(m.goType(firetype.K))).Do(m.goType(firetype.T)) }
+		}
 		if firetype.T != nil {
 			return func(s *jen.Statement) { s.Map(jen.String()).Do(m.goType(firetype.T)) }
 		}
@@ -2,6 +2,8 @@ package ios
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"text/template"
 
 	"github.com/iancoleman/strcase"
@@ -23,6 +25,12 @@ func (m *Modeler) Model(schema *firemodel.Schema, sourceCoder firemodel.SourceCo
 	}
 	defer f.Close()
 
+	tpl := template.Must(tpl.Clone()).Funcs(map[string]interface{}{
+		"hasValidation": schema.HasValidation,
+		"validations": func(fields []*firemodel.SchemaField) string {
+			return swiftValidations(schema, fields)
+		},
+	})
 	if err := tpl.Execute(f, schema); err != nil {
 		return errors.Wrapf(err, "firemodel/ios: generating swift")
 	}
@@ -35,6 +43,9 @@ var (
 		Funcs(map[string]interface{}{
 			"firemodelVersion":             func() string { return version.Version },
 			"toSwiftType":                  toSwiftType,
+			"swiftFieldType":               swiftFieldType,
+			"swiftLiteral":                 swiftLiteral,
+			"swiftBacking":                 swiftBacking,
 			"toScreamingSnake":             strcase.ToScreamingSnake,
 			"toCamel":                      strcase.ToCamel,
 			"toLowerCamel":                 strcase.ToLowerCamel,
@@ -43,14 +54,28 @@ var (
 			"filterFieldsStructsOnly":      filterFieldsStructsOnly,
 			"filterFieldsStructArraysOnly": filterFieldsStructArraysOnly,
 			"filterFieldsEnumArraysOnly":   filterFieldsEnumArraysOnly,
+			"filterFieldsUnionsOnly":       filterFieldsUnionsOnly,
+			"filterFieldsUnionArraysOnly":  filterFieldsUnionArraysOnly,
+			"filterFieldsKeyedMapsOnly":    filterFieldsKeyedMapsOnly,
+			"encodeKeyedMap":               encodeKeyedMap,
+			"decodeKeyedMap":               decodeKeyedMap,
+			"isDynamic":                    isDynamic,
 			"requiresCustomEncodeDecode":   requiresCustomEncodeDecode,
 			"firestoreModelName":           firestoreModelName,
+			"typeName":                     typeName,
+			"swiftProperty":                swiftProperty,
+			"isSwiftAliased":               isSwiftAliased,
+			// Bound to the schema being modeled.
+			"hasValidation": func([]*firemodel.SchemaField) bool { return false },
+			"validations":   func([]*firemodel.SchemaField) string { return "" },
 		}).
 		Parse(file),
 	)
 	_ = template.Must(tpl.New("model").Parse(model))
 	_ = template.Must(tpl.New("enum").Parse(enum))
 	_ = template.Must(tpl.New("struct").Parse(structTpl))
+	_ = template.Must(tpl.New("union").Parse(union))
+	_ = template.Must(tpl.New("validator").Parse(validator))
 )
 
 func requiresCustomEncodeDecode(in []*firemodel.SchemaField) bool {
@@ -63,6 +88,12 @@ func requiresCustomEncodeDecode(in []*firemodel.SchemaField) bool {
 	if len(filterFieldsStructArraysOnly(in)) > 0 {
 		return true
 	}
+	if len(filterFieldsUnionsOnly(in)) > 0 || len(filterFieldsUnionArraysOnly(in)) > 0 {
+		return true
+	}
+	if len(filterFieldsKeyedMapsOnly(in)) > 0 {
+		return true
+	}
 	return false
 }
 
@@ -129,6 +160,328 @@ func filterFieldsEnumArraysOnly(in []*firemodel.SchemaField) []*firemodel.Schema
 	return out
 }
 
+func filterFieldsUnionsOnly(in []*firemodel.SchemaField) []*firemodel.SchemaField {
+	var out []*firemodel.SchemaField
+	for _, i := range in {
+		if _, ok := i.Type.(*firemodel.Union); !ok {
+			continue
+		}
+		out = append(out, i)
+	}
+	return out
+}
+
+func filterFieldsUnionArraysOnly(in []*firemodel.SchemaField) []*firemodel.SchemaField {
+	var out []*firemodel.SchemaField
+	for _, i := range in {
+		t, ok := i.Type.(*firemodel.Array)
+		if !ok {
+			continue
+		}
+		if _, ok := t.T.(*firemodel.Union); !ok {
+			continue
+		}
+		out = append(out, i)
+	}
+	return out
+}
+
+// filterFieldsKeyedMapsOnly returns the fields that are maps with enum keys, which are encoded with
+// the stored values of their keys.
+func filterFieldsKeyedMapsOnly(in []*firemodel.SchemaField) []*firemodel.SchemaField {
+	var out []*firemodel.SchemaField
+	for _, i := range in {
+		if t, ok := i.Type.(*firemodel.Map); !ok || t.K == nil {
+			continue
+		}
+		out = append(out, i)
+	}
+	return out
+}
+
+// isDynamic returns true if the property generated for field can be declared dynamic. Unions are
+// swift enums with associated values, and dictionaries can only have string keys in Objective-C,
+// so neither can be represented there.
+func isDynamic(field *firemodel.SchemaField) bool {
+	switch firetype := field.Type.(type) {
+	case *firemodel.Union:
+		return false
+	case *firemodel.Map:
+		return firetype.K == nil
+	case *firemodel.Array:
+		_, ok := firetype.T.(*firemodel.Union)
+		return !ok
+	default:
+		return true
+	}
+}
+
+// swiftName returns the name of a generated type. Swift has no namespaces, so types declared in a
+// schema package are prefixed with the package name.
+func swiftName(pkg string, name string) string {
+	return strcase.ToCamel(pkg) + strcase.ToCamel(name)
+}
+
+func typeName(decl interface{}) string {
+	switch decl := decl.(type) {
+	case *firemodel.SchemaModel:
+		return swiftName(decl.Package, decl.Name)
+	case *firemodel.SchemaStruct:
+		return swiftName(decl.Package, decl.Name)
+	case *firemodel.SchemaEnum:
+		return swiftName(decl.Package, decl.Name)
+	case *firemodel.SchemaUnion:
+		return swiftName(decl.Package, decl.Name)
+	case *firemodel.SchemaAlias:
+		return swiftName(decl.Package, decl.Name)
+	default:
+		err := errors.Errorf("firemodel/ios: unknown declaration %T", decl)
+		panic(err)
+	}
+}
+
+// swiftFieldType returns the type of the property generated for field, including its initial
+// value. Pring properties must be representable in Objective-C, so numbers and booleans can't be
+// optionals and default to zero instead.
+func swiftFieldType(field *firemodel.SchemaField) string {
+	swiftType := toSwiftType(true, field.Type)
+	if field.IsRequired() && !field.Nullable {
+		switch field.Type.(type) {
+		case *firemodel.String:
+			swiftType = `String = ""`
+		case *firemodel.Bytes:
+			swiftType = "Data = Data()"
+		}
+	}
+	if field.IsOptional() || field.Nullable {
+		if _, ok := field.Type.(*firemodel.Map); ok {
+			swiftType = strings.TrimSuffix(swiftType, " = [:]") + "?"
+		}
+	}
+	if field.Alias != nil && isSwiftAliased(field.Type) {
+		// Scalars are declared with the typealias of the alias.
+		swiftType = swiftName(field.Alias.Package, field.Alias.Name) + strings.TrimPrefix(swiftType, toSwiftType(false, field.Type))
+	}
+	if field.Default != nil {
+		swiftType = fmt.Sprintf("%s = %s", strings.SplitN(swiftType, " = ", 2)[0], swiftValue(field))
+	}
+	return swiftType
+}
+
+// isSwiftAliased returns true if aliases of firetype are generated as a typealias. Collections
+// keep the types Pring expects of their properties, so they are declared with their base type.
+func isSwiftAliased(firetype firemodel.SchemaFieldType) bool {
+	switch firetype.(type) {
+	case *firemodel.Boolean,
+		*firemodel.Integer,
+		*firemodel.Double,
+		*firemodel.Timestamp,
+		*firemodel.String,
+		*firemodel.Bytes,
+		*firemodel.URL,
+		*firemodel.GeoPoint:
+		return true
+	default:
+		return false
+	}
+}
+
+// swiftValue returns the default value of field as a swift literal.
+func swiftValue(field *firemodel.SchemaField) string {
+	switch value := field.Default.(type) {
+	case *firemodel.SchemaEnumValue:
+		return fmt.Sprintf(".%s", strcase.ToLowerCamel(value.Name))
+	case string:
+		if _, ok := field.Type.(*firemodel.URL); ok {
+			return fmt.Sprintf("URL(string: %s)", strconv.Quote(value))
+		}
+		return strconv.Quote(value)
+	default:
+		return swiftLiteral(value)
+	}
+}
+
+func swiftLiteral(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprint(value)
+}
+
+// swiftValidations returns the statements that check the validation constraints of fields,
+// returning a description of the first violation.
+func swiftValidations(schema *firemodel.Schema, fields []*firemodel.SchemaField) string {
+	var b strings.Builder
+	line := func(depth int, format string, args ...interface{}) {
+		b.WriteString("\n")
+		b.WriteString(strings.Repeat("    ", depth))
+		fmt.Fprintf(&b, format, args...)
+	}
+
+	for _, field := range fields {
+		structType := schema.NestedStruct(field.Type)
+		if structType != nil && !schema.HasValidation(structType.Fields) {
+			structType = nil
+		}
+		if len(field.Constraints) == 0 && structType == nil {
+			continue
+		}
+
+		_, isStruct := field.Type.(*firemodel.Struct)
+		optional := strings.HasSuffix(strings.SplitN(swiftFieldType(field), " = ", 2)[0], "?")
+		switch {
+		case !optional:
+			line(2, "do {")
+			line(3, "let v = self.%s", swiftProperty(field.WireName))
+		case field.IsOptional() || field.Nullable || isStruct:
+			line(2, "if let v = self.%s {", swiftProperty(field.WireName))
+		default:
+			line(2, "do {")
+			line(3, "let v = self.%s ?? %s", swiftProperty(field.WireName), swiftZero(field.Type))
+		}
+
+		for _, constraint := range field.Constraints {
+			var violated string
+			switch constraint.Kind {
+			case firemodel.ConstraintMin:
+				violated = fmt.Sprintf("v < %s", constraint.FormatLimit())
+			case firemodel.ConstraintMax:
+				violated = fmt.Sprintf("v > %s", constraint.FormatLimit())
+			case firemodel.ConstraintMinLength:
+				violated = fmt.Sprintf("v.unicodeScalars.count < %s", constraint.FormatLimit())
+			case firemodel.ConstraintMaxLength:
+				violated = fmt.Sprintf("v.unicodeScalars.count > %s", constraint.FormatLimit())
+			case firemodel.ConstraintPattern:
+				violated = fmt.Sprintf("v.range(of: %s, options: .regularExpression) == nil", swiftLiteral(constraint.Pattern))
+			case firemodel.ConstraintNonEmpty:
+				violated = "v.isEmpty"
+			case firemodel.ConstraintMinItems:
+				violated = fmt.Sprintf("v.count < %s", constraint.FormatLimit())
+			case firemodel.ConstraintMaxItems:
+				violated = fmt.Sprintf("v.count > %s", constraint.FormatLimit())
+			default:
+				continue
+			}
+			line(3, "if %s {", violated)
+			line(4, "return %s", swiftLiteral(fmt.Sprintf("%s: %s", field.WireName, constraint.Message())))
+			line(3, "}")
+		}
+
+		if structType != nil {
+			if isStruct {
+				line(3, "if let err = v.validate() {")
+				line(4, `return "%s.\(err)"`, field.WireName)
+				line(3, "}")
+			} else {
+				line(3, "for (i, item) in v.enumerated() {")
+				line(4, "if let err = item.validate() {")
+				line(5, `return "%s[\(i)].\(err)"`, field.WireName)
+				line(4, "}")
+				line(3, "}")
+			}
+		}
+		line(2, "}")
+	}
+	return b.String()
+}
+
+// encodeKeyedMap returns the body of the encode case of field, which is a map with enum keys.
+func encodeKeyedMap(field *firemodel.SchemaField) string {
+	var b strings.Builder
+	line := func(depth int, format string, args ...interface{}) {
+		b.WriteString("\n")
+		b.WriteString(strings.Repeat("    ", depth))
+		fmt.Fprintf(&b, format, args...)
+	}
+
+	mapType := field.Type.(*firemodel.Map)
+	var item string
+	switch mapType.T.(type) {
+	case *firemodel.Enum, *firemodel.Union:
+		item = "item.firestoreValue"
+	case *firemodel.Struct:
+		item = "item.rawValue"
+	default:
+		item = "item"
+	}
+
+	if field.IsOptional() || field.Nullable {
+		line(3, "guard let map = self.%s else {", swiftProperty(field.WireName))
+		line(4, "return nil")
+		line(3, "}")
+	} else {
+		line(3, "let map = self.%s", swiftProperty(field.WireName))
+	}
+	line(3, "var data: [String: Any] = [:]")
+	line(3, "for (key, item) in map {")
+	line(4, "if let key = key.firestoreValue {")
+	line(5, "data[key] = %s", item)
+	line(4, "}")
+	line(3, "}")
+	line(3, "return data")
+	return b.String()
+}
+
+// decodeKeyedMap returns the body of the decode case of field, which is a map with enum keys.
+// Entries with unknown keys or invalid values are dropped.
+func decodeKeyedMap(field *firemodel.SchemaField) string {
+	var b strings.Builder
+	line := func(depth int, format string, args ...interface{}) {
+		b.WriteString("\n")
+		b.WriteString(strings.Repeat("    ", depth))
+		fmt.Fprintf(&b, format, args...)
+	}
+
+	mapType := field.Type.(*firemodel.Map)
+	keyType := toSwiftType(false, mapType.K)
+	valueType := toSwiftType(false, mapType.T)
+	var item string
+	switch mapType.T.(type) {
+	case *firemodel.Enum, *firemodel.Union:
+		item = fmt.Sprintf("%s(firestoreValue: item)", valueType)
+	case *firemodel.Struct:
+		item = fmt.Sprintf(`(item as? [String: Any]).map({ %s(id: "%s.\(key)", value: $0) })`, valueType, field.WireName)
+	default:
+		item = fmt.Sprintf("item as? %s", valueType)
+	}
+
+	line(3, "guard let value = value as? [String: Any] else {")
+	line(4, "return false")
+	line(3, "}")
+	line(3, "var map: [%s: %s] = [:]", keyType, valueType)
+	line(3, "for (key, item) in value {")
+	line(4, "if let item = %s, let key = %s(firestoreValue: key) {", item, keyType)
+	line(5, "map[key] = item")
+	line(4, "}")
+	line(3, "}")
+	line(3, "self.%s = map", swiftProperty(field.WireName))
+	line(3, "return true")
+	return b.String()
+}
+
+// swiftZero returns the value that a missing field of type firetype is validated as.
+func swiftZero(firetype firemodel.SchemaFieldType) string {
+	switch firetype.(type) {
+	case *firemodel.String:
+		return `""`
+	case *firemodel.Array:
+		return "[]"
+	case *firemodel.Map:
+		return "[:]"
+	default:
+		err := errors.Errorf("firemodel/ios: can't validate missing %s", firetype)
+		panic(err)
+	}
+}
+
+// swiftBacking returns the swift type that values of enum are stored as.
+func swiftBacking(enum *firemodel.SchemaEnum) string {
+	if enum.IsIntegerBacked() {
+		return "Int"
+	}
+	return "String"
+}
+
 func toSwiftType(root bool, firetype firemodel.SchemaFieldType) string {
 	switch firetype := firetype.(type) {
 	case *firemodel.Boolean:
@@ -176,7 +529,7 @@ func toSwiftType(root bool, firetype firemodel.SchemaFieldType) string {
 	case *firemodel.Reference:
 		if firetype.T != nil {
 			if root {
-				return fmt.Sprintf("Pring.Reference<%s> = .init()", strcase.ToCamel(firetype.T.Name))
+				return fmt.Sprintf("Pring.Reference<%s> = .init()", typeName(firetype.T))
 			} else {
 				// HACK: Pring does not decode [Reference<T>] correctly. Use [Any] until this is fixed.
 				//       https://github.com/1amageek/Pring/issues/49
@@ -210,17 +563,26 @@ func toSwiftType(root bool, firetype firemodel.SchemaFieldType) string {
 		}
 	case *firemodel.Struct:
 		if root {
-			return fmt.Sprintf("%s?", firetype.T.Name)
+			return fmt.Sprintf("%s?", typeName(firetype.T))
 		} else {
-			return firetype.T.Name
+			return typeName(firetype.T)
 		}
 	case *firemodel.Enum:
 		if root {
-			return fmt.Sprintf("%s?", strcase.ToCamel(firetype.T.Name))
+			return fmt.Sprintf("%s?", typeName(firetype.T))
 		} else {
-			return strcase.ToCamel(firetype.T.Name)
+			return typeName(firetype.T)
+		}
+	case *firemodel.Union:
+		if root {
+			return fmt.Sprintf("%s?", typeName(firetype.T))
+		} else {
+			return typeName(firetype.T)
 		}
 	case *firemodel.Map:
+		if firetype.K != nil {
+			return fmt.Sprintf("[%s: %s] = [:]", toSwiftType(false, firetype.K), toSwiftType(false, firetype.T))
+		}
 		if firetype.T != nil {
 			return fmt.Sprintf("[String: %s] = [:]", toSwiftType(false, firetype.T))
 		} else {
@@ -232,6 +594,28 @@ func toSwiftType(root bool, firetype firemodel.SchemaFieldType) string {
 	}
 }
 
+// swiftKeywords are the Swift keywords that can't be used as property names without escaping.
+var swiftKeywords = map[string]bool{
+	"Any": true, "Self": true, "as": true, "associatedtype": true, "break": true, "case": true,
+	"catch": true, "class": true, "continue": true, "default": true, "defer": true, "deinit": true,
+	"do": true, "else": true, "enum": true, "extension": true, "fallthrough": true, "false": true,
+	"fileprivate": true, "for": true, "func": true, "guard": true, "if": true, "import": true,
+	"in": true, "init": true, "inout": true, "internal": true, "is": true, "let": true, "nil": true,
+	"open": true, "operator": true, "private": true, "protocol": true, "public": true,
+	"repeat": true, "rethrows": true, "return": true, "self": true, "static": true, "struct": true,
+	"subscript": true, "super": true, "switch": true, "throw": true, "throws": true, "true": true,
+	"try": true, "typealias": true, "var": true, "where": true, "while": true,
+}
+
+// swiftProperty returns name as a Swift identifier, escaping it with backticks if it is a keyword.
+// The name of the property doesn't change, so Pring still stores it under name.
+func swiftProperty(name string) string {
+	if swiftKeywords[name] {
+		return "`" + name + "`"
+	}
+	return name
+}
+
 func firestoreModelName(model firemodel.SchemaModel) string {
 	modelName, err := model.Options.GetFirestoreModelName()
 	if err != nil {
@@ -246,12 +630,21 @@ const (
 
 import Foundation
 import Pring
-{{range .Enums -}}
+{{range .Aliases}}{{if isSwiftAliased .Type}}
+{{- if .Comment}}
+// {{.Comment}}
+{{- end}}
+typealias {{typeName .}} = {{.Type | toSwiftType false}}
+{{end}}{{end}}
+{{- range .Enums -}}
 {{template "enum" .}}
 {{- end}}
 {{range .Structs -}}
 {{template "struct" .}}
 {{- end}}
+{{- range .Unions -}}
+{{template "union" .}}
+{{- end}}
 {{- range .Models -}}
 {{- template "model" .}}
 {{- end -}}`
@@ -260,7 +653,7 @@ import Pring
 {{- if .Comment}}
 // {{.Comment}}
 {{- end}}
-@objcMembers class {{.Name | toCamel}}: Pring.Object {
+@objcMembers class {{typeName .}}: Pring.Object {
 	{{- if firestoreModelName . }}
 override class var path: String { return "{{firestoreModelName . }}" }
 	{{- end}}
@@ -268,33 +661,48 @@ override class var path: String { return "{{firestoreModelName . }}" }
     {{- if .Comment}}
     // {{.Comment}}
     {{- end}}
-    dynamic var {{.Name | toLowerCamel -}}: {{.Type | toSwiftType true}}
+    {{- if .Deprecated}}
+    @available(*, deprecated)
+    {{- end}}
+    {{if isDynamic .}}dynamic {{end}}var {{swiftProperty .WireName -}}: {{swiftFieldType .}}
     {{- end}}
     {{- range .Collections}}
     {{- if .Comment}}
     // {{.Comment}}
     {{- end}}
-    dynamic var {{.Name | toLowerCamel}}: Pring.NestedCollection<{{.Type.Name}}> = []
+    dynamic var {{.Name | toLowerCamel}}: Pring.NestedCollection<{{typeName .Type}}> = []
     {{- end}}
     {{- if .Fields | requiresCustomEncodeDecode }}
 
     override func encode(_ key: String, value: Any?) -> Any? {
         switch key {
         {{- range .Fields | filterFieldsEnumsOnly}}
-        case "{{.Name | toLowerCamel}}":
-            return self.{{.Name | toLowerCamel}}?.firestoreValue
+        case "{{.WireName}}":
+            return self.{{swiftProperty .WireName}}?.firestoreValue
         {{- end}}
         {{- range .Fields | filterFieldsStructArraysOnly}}
-        case "{{.Name | toLowerCamel}}":
-            return self.{{.Name | toLowerCamel}}?.map { $0.rawValue }
+        case "{{.WireName}}":
+            return self.{{swiftProperty .WireName}}?.map { $0.rawValue }
         {{- end}}
         {{- range .Fields | filterFieldsStructsOnly}}
-        case "{{.Name | toLowerCamel}}":
-            return self.{{.Name | toLowerCamel}}?.rawValue
+        case "{{.WireName}}":
+            return self.{{swiftProperty .WireName}}?.rawValue
         {{- end}}
         {{- range .Fields | filterFieldsEnumArraysOnly}}
-        case "{{.Name | toLowerCamel}}":
-            return self.{{.Name | toLowerCamel}}?.map { $0.firestoreValue }
+        case "{{.WireName}}":
+            return self.{{swiftProperty .WireName}}?.map { $0.firestoreValue }
+        {{- end}}
+        {{- range .Fields | filterFieldsUnionsOnly}}
+        case "{{.WireName}}":
+            return self.{{swiftProperty .WireName}}?.firestoreValue
+        {{- end}}
+        {{- range .Fields | filterFieldsUnionArraysOnly}}
+        case "{{.WireName}}":
+            return self.{{swiftProperty .WireName}}?.map { $0.firestoreValue }
+        {{- end}}
+        {{- range .Fields | filterFieldsKeyedMapsOnly}}
+        case "{{.WireName}}":
+            {{- encodeKeyedMap .}}
         {{- end}}
         default:
             break
@@ -305,56 +713,126 @@ override class var path: String { return "{{firestoreModelName . }}" }
     override func decode(_ key: String, value: Any?) -> Bool {
         switch key {
         {{- range .Fields | filterFieldsEnumsOnly}}
-        case "{{.Name | toLowerCamel}}":
-            self.{{.Name | toLowerCamel}} = {{.Type | toSwiftType false }}(firestoreValue: value)
+        case "{{.WireName}}":
+            self.{{swiftProperty .WireName}} = {{.Type | toSwiftType false }}(firestoreValue: value)
         {{- end}}
         {{- range .Fields | filterFieldsStructArraysOnly}}
-        case "{{.Name | toLowerCamel}}":
-            self.{{.Name | toLowerCamel}} = (value as? [[String: Any]])?
+        case "{{.WireName}}":
+            self.{{swiftProperty .WireName}} = (value as? [[String: Any]])?
                 .enumerated()
-                .map { {{.Type.T | toSwiftType false }}(id: "{{.Name | toLowerCamel}}.\($0.offset)", value: $0.element) }
+                .map { {{.Type.T | toSwiftType false }}(id: "{{.WireName}}.\($0.offset)", value: $0.element) }
         {{- end}}
         {{- range .Fields | filterFieldsStructsOnly}}
-        case "{{.Name | toLowerCamel}}":
+        case "{{.WireName}}":
           if let value = value as? [String: Any] {
-            self.{{.Name | toLowerCamel}} = {{.Type | toSwiftType false}}(id: "\(0)", value: value)
+            self.{{swiftProperty .WireName}} = {{.Type | toSwiftType false}}(id: "\(0)", value: value)
             return true
           }
           {{- end}}
         {{- range .Fields | filterFieldsEnumArraysOnly}}
-        case "{{.Name | toLowerCamel}}":
-            self.{{.Name | toLowerCamel}} = (value as? [String])?.compactMap { {{.Type.T | toSwiftType false }}(firestoreValue: $0) }
+        case "{{.WireName}}":
+            self.{{swiftProperty .WireName}} = (value as? [{{swiftBacking .Type.T.T}}])?.compactMap { {{.Type.T | toSwiftType false }}(firestoreValue: $0) }
 			return true
         {{- end}}
+        {{- range .Fields | filterFieldsUnionsOnly}}
+        case "{{.WireName}}":
+            self.{{swiftProperty .WireName}} = {{.Type | toSwiftType false }}(firestoreValue: value)
+            return true
+        {{- end}}
+        {{- range .Fields | filterFieldsUnionArraysOnly}}
+        case "{{.WireName}}":
+            self.{{swiftProperty .WireName}} = (value as? [Any])?.compactMap { {{.Type.T | toSwiftType false }}(firestoreValue: $0) }
+            return true
+        {{- end}}
+        {{- range .Fields | filterFieldsKeyedMapsOnly}}
+        case "{{.WireName}}":
+            {{- decodeKeyedMap .}}
+        {{- end}}
         default:
             break
         }
         return false
     }
     {{- end}}
+    {{- template "validator" .}}
+}
+`
+	validator = `
+    {{- if hasValidation .Fields}}
+
+    // Returns a description of the first validation constraint that the object violates, if any.
+    func validate() -> String? {
+        {{- validations .Fields}}
+        return nil
+    }
+    {{- end}}`
+
+	union = `
+{{- if .Comment}}
+// {{.Comment}}
+{{- end}}
+enum {{typeName .}} {
+    {{- range .Members}}
+    {{- if .Comment}}
+    // {{.Comment}}
+    {{- end}}
+    case {{.Name | toLowerCamel}}({{.Type | toSwiftType false}})
+    {{- end}}
+}
+
+extension {{typeName .}} {
+    init?(firestoreValue value: Any?) {
+        guard let value = value as? [String: Any], let member = value["{{.Discriminator}}"] as? String else {
+            return nil
+        }
+        switch member {
+        {{- range .Members}}
+        case "{{.WireName}}":
+            guard let data = value["{{.WireName}}"] as? [String: Any] else {
+                return nil
+            }
+            self = .{{.Name | toLowerCamel}}({{.Type | toSwiftType false}}(id: "{{.WireName}}", value: data))
+        {{- end}}
+        default:
+            return nil
+        }
+    }
+
+    var firestoreValue: [String: Any] {
+        switch self {
+        {{- range .Members}}
+        case .{{.Name | toLowerCamel}}(let member):
+            return ["{{$.Discriminator}}": "{{.WireName}}", "{{.WireName}}": member.rawValue]
+        {{- end}}
+        }
+    }
 }
 `
+
 	enum = `
 {{- if .Comment}}
 // {{.Comment}}
 {{- end}}
-@objc enum {{.Name | toCamel }}: Int {
+@objc enum {{typeName .}}: Int {
     {{- range .Values}}
     {{- if .Comment}}
     // {{.Comment}}
     {{- end}}
+    {{- if .Deprecated}}
+    // Deprecated: kept to decode existing documents, don't store it in new ones.
+    {{- end}}
     case {{.Name | toLowerCamel}}
     {{- end}}
 }
 
-extension {{.Name | toCamel}}: CustomDebugStringConvertible {
+extension {{typeName .}}: CustomDebugStringConvertible {
     init?(firestoreValue value: Any?) {
-        guard let value = value as? String else {
+        guard let value = value as? {{swiftBacking .}} else {
             return nil
         }
         switch value {
         {{- range $v := .Values}}
-        case "{{$v.Name | toScreamingSnake}}":
+        case {{swiftLiteral $v.WireValue}}{{range $v.Aliases}}, {{swiftLiteral .}}{{end}}:
             self = .{{$v.Name | toLowerCamel }}
         {{- end}}
         default:
@@ -362,48 +840,63 @@ extension {{.Name | toCamel}}: CustomDebugStringConvertible {
         }
     }
 
-    var firestoreValue: String? {
+    var firestoreValue: {{swiftBacking .}}? {
         switch self {
         {{- range .Values}}
         case .{{.Name | toLowerCamel}}:
-            return "{{.Name | toScreamingSnake}}"
+            return {{swiftLiteral .WireValue}}
         {{- end}}
         }
     }
 
-    var debugDescription: String { return firestoreValue ?? "<INVALID>" }
+    var debugDescription: String { return {{if .IsIntegerBacked}}firestoreValue.map { String($0) }{{else}}firestoreValue{{end}} ?? "<INVALID>" }
 }`
 
 	structTpl = `
 {{- if .Comment}}
 // {{.Comment}}
 {{- end}}
-@objcMembers class {{.Name | toCamel }}: Pring.Object {
+@objcMembers class {{typeName .}}: Pring.Object {
     {{- range .Fields}}
     {{- if .Comment}}
     // {{.Comment}}
     {{- end}}
-    var {{.Name | toLowerCamel -}}: {{.Type | toSwiftType true}}
+    {{- if .Deprecated}}
+    @available(*, deprecated)
+    {{- end}}
+    var {{swiftProperty .WireName -}}: {{swiftFieldType .}}
     {{- end}}
     {{- if .Fields | requiresCustomEncodeDecode }}
 
     override func encode(_ key: String, value: Any?) -> Any? {
         switch key {
         {{- range .Fields | filterFieldsEnumsOnly}}
-        case "{{.Name | toLowerCamel}}":
-            return self.{{.Name | toLowerCamel}}?.firestoreValue
+        case "{{.WireName}}":
+            return self.{{swiftProperty .WireName}}?.firestoreValue
         {{- end}}
         {{- range .Fields | filterFieldsStructArraysOnly}}
-        case "{{.Name | toLowerCamel}}":
-            return self.{{.Name | toLowerCamel}}?.map { $0.rawValue }
+        case "{{.WireName}}":
+            return self.{{swiftProperty .WireName}}?.map { $0.rawValue }
         {{- end}}
         {{- range .Fields | filterFieldsStructsOnly}}
-        case "{{.Name | toLowerCamel}}":
-            return self.{{.Name | toLowerCamel}}?.rawValue
+        case "{{.WireName}}":
+            return self.{{swiftProperty .WireName}}?.rawValue
         {{- end}}
         {{- range .Fields | filterFieldsEnumArraysOnly}}
-        case "{{.Name | toLowerCamel}}":
-            return self.{{.Name | toLowerCamel}}?.map { $0.firestoreValue }
+        case "{{.WireName}}":
+            return self.{{swiftProperty .WireName}}?.map { $0.firestoreValue }
+        {{- end}}
+        {{- range .Fields | filterFieldsUnionsOnly}}
+        case "{{.WireName}}":
+            return self.{{swiftProperty .WireName}}?.firestoreValue
+        {{- end}}
+        {{- range .Fields | filterFieldsUnionArraysOnly}}
+        case "{{.WireName}}":
+            return self.{{swiftProperty .WireName}}?.map { $0.firestoreValue }
+        {{- end}}
+        {{- range .Fields | filterFieldsKeyedMapsOnly}}
+        case "{{.WireName}}":
+            {{- encodeKeyedMap .}}
         {{- end}}
         default:
             break
@@ -414,33 +907,48 @@ extension {{.Name | toCamel}}: CustomDebugStringConvertible {
     override func decode(_ key: String, value: Any?) -> Bool {
         switch key {
         {{- range .Fields | filterFieldsEnumsOnly}}
-        case "{{.Name | toLowerCamel}}":
-            self.{{.Name | toLowerCamel}} = {{.Type | toSwiftType false }}(firestoreValue: value)
+        case "{{.WireName}}":
+            self.{{swiftProperty .WireName}} = {{.Type | toSwiftType false }}(firestoreValue: value)
         {{- end}}
         {{- range .Fields | filterFieldsStructArraysOnly}}
-        case "{{.Name | toLowerCamel}}":
-            self.{{.Name | toLowerCamel}} = (value as? [[String: Any]])?
+        case "{{.WireName}}":
+            self.{{swiftProperty .WireName}} = (value as? [[String: Any]])?
                 .enumerated()
                 .map { {{.Type.T | toSwiftType false }}(id: "\($0.offset)", value: $0.element) }
         {{- end}}
         {{- range .Fields | filterFieldsStructsOnly}}
-        case "{{.Name | toLowerCamel}}":
+        case "{{.WireName}}":
           if let value = value as? [String: Any] {
-            self.{{.Name | toLowerCamel}} = {{.Type | toSwiftType false}}(id: "\(0)", value: value)
+            self.{{swiftProperty .WireName}} = {{.Type | toSwiftType false}}(id: "\(0)", value: value)
             return true
           }
           {{- end}}
         {{- range .Fields | filterFieldsEnumArraysOnly}}
-        case "{{.Name | toLowerCamel}}":
-            self.{{.Name | toLowerCamel}} = (value as? [String])?.compactMap { {{.Type.T | toSwiftType false }}(firestoreValue: $0) }
+        case "{{.WireName}}":
+            self.{{swiftProperty .WireName}} = (value as? [{{swiftBacking .Type.T.T}}])?.compactMap { {{.Type.T | toSwiftType false }}(firestoreValue: $0) }
 			return true
         {{- end}}
+        {{- range .Fields | filterFieldsUnionsOnly}}
+        case "{{.WireName}}":
+            self.{{swiftProperty .WireName}} = {{.Type | toSwiftType false }}(firestoreValue: value)
+            return true
+        {{- end}}
+        {{- range .Fields | filterFieldsUnionArraysOnly}}
+        case "{{.WireName}}":
+            self.{{swiftProperty .WireName}} = (value as? [Any])?.compactMap { {{.Type.T | toSwiftType false }}(firestoreValue: $0) }
+            return true
+        {{- end}}
+        {{- range .Fields | filterFieldsKeyedMapsOnly}}
+        case "{{.WireName}}":
+            {{- decodeKeyedMap .}}
+        {{- end}}
         default:
             break
         }
         return false
     }
     {{- end}}
+    {{- template "validator" .}}
 }
 `
 )
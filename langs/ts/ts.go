@@ -1,7 +1,9 @@
 package ts
 
 import (
+	"bytes"
 	"fmt"
+	"strings"
 	"text/template"
 
 	"github.com/iancoleman/strcase"
@@ -30,7 +32,30 @@ func (m *Modeler) Model(schema *firemodel.Schema, sourceCoder firemodel.SourceCo
 	}
 	defer d.Close()
 
-	if err := tpl.Execute(f, schema); err != nil {
+	tpl := template.Must(tpl.Clone()).Funcs(map[string]interface{}{
+		"hasValidation": schema.HasValidation,
+		"validations": func(fields []*firemodel.SchemaField) string {
+			return typescriptValidations(schema, fields)
+		},
+	})
+
+	data := &fileData{Schema: schema}
+	for _, pkg := range schema.PackageNames() {
+		var buf bytes.Buffer
+		if err := tpl.ExecuteTemplate(&buf, "declarations", schema.InPackage(pkg)); err != nil {
+			return errors.Wrapf(err, "firemodel/ts: generating typescript")
+		}
+		if pkg == "" {
+			data.Declarations = buf.String()
+		} else {
+			data.Packages = append(data.Packages, &packageData{
+				Name:         pkg,
+				Declarations: indent(buf.String()),
+			})
+		}
+	}
+
+	if err := tpl.Execute(f, data); err != nil {
 		return errors.Wrapf(err, "firemodel/ts: generating typescript")
 	}
 
@@ -42,30 +67,246 @@ func (m *Modeler) Model(schema *firemodel.Schema, sourceCoder firemodel.SourceCo
 	return nil
 }
 
+// fileData is rendered by the file template. Declarations in the default package are rendered
+// directly in the top-level namespace, and every other schema package is rendered as a nested
+// namespace.
+type fileData struct {
+	Schema       *firemodel.Schema
+	Declarations string
+	Packages     []*packageData
+}
+
+type packageData struct {
+	Name         string
+	Declarations string
+}
+
+// indent indents every non-empty line after the first newline in s by one level.
+func indent(s string) string {
+	lines := strings.Split(s, "\n")
+	for idx, line := range lines {
+		if idx > 0 && line != "" {
+			lines[idx] = "  " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 var (
 	tpl = template.Must(template.
 		New("file").
 		Funcs(map[string]interface{}{
 			"firemodelVersion": func() string { return version.Version },
 			"toTypescriptType": toTypescriptType,
+			"fieldType":        typescriptFieldType,
 			"ToScreamingSnake": strcase.ToScreamingSnake,
 			"ToLowerCamel":     strcase.ToLowerCamel,
 			"ToCamel":          strcase.ToCamel,
 			"getModelOption":   getModelOption,
 			"getSchemaOption":  getSchemaOption,
 			"interfaceName":    interfaceName,
+			"extends":          extends,
+			"typescriptName":   typescriptName,
+			"hasDefault":       hasDefault,
+			"hasDefaults":      hasDefaults,
+			"typescriptValue":  typescriptValue,
+			"literal":          typescriptLiteral,
+			"hasAliases":       hasAliases,
+			// Bound to the schema being modeled.
+			"hasValidation": func([]*firemodel.SchemaField) bool { return false },
+			"validations":   func([]*firemodel.SchemaField) string { return "" },
 		}).
 		Parse(file),
 	)
+	_ = template.Must(tpl.New("declarations").Parse(declarations))
 	_ = template.Must(tpl.New("model").Parse(model))
 	_ = template.Must(tpl.New("enum").Parse(enum))
 	_ = template.Must(tpl.New("struct").Parse(structTpl))
+	_ = template.Must(tpl.New("union").Parse(union))
+	_ = template.Must(tpl.New("alias").Parse(alias))
+	_ = template.Must(tpl.New("factory").Parse(factory))
+	_ = template.Must(tpl.New("validator").Parse(validator))
 )
 
 func interfaceName(sym string) string {
 	return fmt.Sprintf("I%s", sym)
 }
 
+// extends returns the extends clause of the interface of a model or struct that extends structs.
+func extends(structs []*firemodel.SchemaStruct) string {
+	if len(structs) == 0 {
+		return ""
+	}
+	var names []string
+	for _, structType := range structs {
+		names = append(names, typescriptName(structType.Package, interfaceName(structType.Name)))
+	}
+	return fmt.Sprintf(" extends %s", strings.Join(names, ", "))
+}
+
+// typescriptName qualifies the name of a generated type with the namespace of its schema package.
+func typescriptName(pkg string, sym string) string {
+	if pkg == "" {
+		return sym
+	}
+	return fmt.Sprintf("%s.%s", pkg, sym)
+}
+
+func hasDefault(field *firemodel.SchemaField) bool {
+	return field.Default != nil
+}
+
+// hasDefaults returns true if any of fields has a default value.
+func hasDefaults(fields []*firemodel.SchemaField) bool {
+	for _, field := range fields {
+		if field.Default != nil {
+			return true
+		}
+	}
+	return false
+}
+
+var typescriptStringEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`, "\r", `\r`)
+
+// typescriptValue returns the default value of field as a typescript literal.
+func typescriptValue(field *firemodel.SchemaField) string {
+	switch value := field.Default.(type) {
+	case *firemodel.SchemaEnumValue:
+		enum := field.Type.(*firemodel.Enum).T
+		return fmt.Sprintf("%s.%s", typescriptName(enum.Package, enum.Name), value.Name)
+	default:
+		if field.Alias != nil {
+			return fmt.Sprintf("%s as %s", typescriptLiteral(value), typescriptFieldType(field))
+		}
+		return typescriptLiteral(value)
+	}
+}
+
+// typescriptFieldType returns the type of field, which is the name of its alias if it was declared
+// with one.
+func typescriptFieldType(field *firemodel.SchemaField) string {
+	if field.Alias != nil {
+		return typescriptName(field.Alias.Package, field.Alias.Name)
+	}
+	return toTypescriptType(field.Type)
+}
+
+func typescriptLiteral(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return fmt.Sprintf("'%s'", typescriptStringEscaper.Replace(s))
+	}
+	return fmt.Sprint(value)
+}
+
+// typescriptValidations returns the statements that check the validation constraints of fields,
+// returning a description of the first violation.
+func typescriptValidations(schema *firemodel.Schema, fields []*firemodel.SchemaField) string {
+	var b strings.Builder
+	line := func(depth int, format string, args ...interface{}) {
+		b.WriteString("\n")
+		b.WriteString(strings.Repeat("  ", depth))
+		fmt.Fprintf(&b, format, args...)
+	}
+
+	for _, field := range fields {
+		structType := schema.NestedStruct(field.Type)
+		if structType != nil && !schema.HasValidation(structType.Fields) {
+			structType = nil
+		}
+		if len(field.Constraints) == 0 && structType == nil {
+			continue
+		}
+
+		_, isStruct := field.Type.(*firemodel.Struct)
+		if field.IsOptional() || field.Nullable || (isStruct && !field.IsRequired()) {
+			line(2, "if (m.%s != null) {", field.WireName)
+			line(3, "const v = m.%s;", field.WireName)
+		} else if field.IsRequired() || isStruct {
+			line(2, "{")
+			line(3, "const v = m.%s;", field.WireName)
+		} else {
+			line(2, "{")
+			line(3, "const v = m.%s || %s;", field.WireName, typescriptZero(field.Type))
+		}
+
+		for _, constraint := range field.Constraints {
+			var violated string
+			switch constraint.Kind {
+			case firemodel.ConstraintMin:
+				violated = fmt.Sprintf("v < %s", constraint.FormatLimit())
+			case firemodel.ConstraintMax:
+				violated = fmt.Sprintf("v > %s", constraint.FormatLimit())
+			case firemodel.ConstraintMinLength:
+				violated = fmt.Sprintf("Array.from(v).length < %s", constraint.FormatLimit())
+			case firemodel.ConstraintMaxLength:
+				violated = fmt.Sprintf("Array.from(v).length > %s", constraint.FormatLimit())
+			case firemodel.ConstraintPattern:
+				violated = fmt.Sprintf("!new RegExp(%s).test(v)", typescriptLiteral(constraint.Pattern))
+			case firemodel.ConstraintNonEmpty:
+				if _, ok := field.Type.(*firemodel.Map); ok {
+					violated = "Object.keys(v).length === 0"
+				} else {
+					violated = "v.length === 0"
+				}
+			case firemodel.ConstraintMinItems:
+				violated = fmt.Sprintf("v.length < %s", constraint.FormatLimit())
+			case firemodel.ConstraintMaxItems:
+				violated = fmt.Sprintf("v.length > %s", constraint.FormatLimit())
+			default:
+				continue
+			}
+			line(3, "if (%s) {", violated)
+			line(4, "return %s;", typescriptLiteral(fmt.Sprintf("%s: %s", field.WireName, constraint.Message())))
+			line(3, "}")
+		}
+
+		if structType != nil {
+			validate := typescriptName(structType.Package, "validate"+strcase.ToCamel(structType.Name))
+			if isStruct {
+				line(3, "const err = %s(v);", validate)
+				line(3, "if (err !== undefined) {")
+				line(4, "return `%s.${err}`;", field.WireName)
+				line(3, "}")
+			} else {
+				line(3, "for (let i = 0; i < v.length; i++) {")
+				line(4, "const err = %s(v[i]);", validate)
+				line(4, "if (err !== undefined) {")
+				line(5, "return `%s[${i}].${err}`;", field.WireName)
+				line(4, "}")
+				line(3, "}")
+			}
+		}
+		line(2, "}")
+	}
+	return b.String()
+}
+
+// typescriptZero returns the value that a missing field of type firetype is validated as.
+func typescriptZero(firetype firemodel.SchemaFieldType) string {
+	switch firetype.(type) {
+	case *firemodel.Integer, *firemodel.Double:
+		return "0"
+	case *firemodel.String:
+		return "''"
+	case *firemodel.Array:
+		return "[]"
+	case *firemodel.Map:
+		return "{}"
+	default:
+		err := errors.Errorf("firemodel/ts: can't validate missing %s", firetype)
+		panic(err)
+	}
+}
+
+func hasAliases(enum *firemodel.SchemaEnum) bool {
+	for _, val := range enum.Values {
+		if len(val.Aliases) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 func toTypescriptType(firetype firemodel.SchemaFieldType) string {
 	switch firetype := firetype.(type) {
 	case *firemodel.Boolean:
@@ -77,14 +318,14 @@ func toTypescriptType(firetype firemodel.SchemaFieldType) string {
 	case *firemodel.String:
 		return "string"
 	case *firemodel.Enum:
-		return firetype.T.Name
+		return typescriptName(firetype.T.Package, firetype.T.Name)
 	case *firemodel.URL:
 		return "URL"
 	case *firemodel.Bytes:
 		return "firestore.Blob"
 	case *firemodel.Reference:
 		if firetype.T != nil {
-			return fmt.Sprintf("firestore.DocumentReference<%s>", interfaceName(firetype.T.Name))
+			return fmt.Sprintf("firestore.DocumentReference<%s>", typescriptName(firetype.T.Package, interfaceName(firetype.T.Name)))
 		} else {
 			return "firestore.DocumentReference"
 		}
@@ -97,10 +338,15 @@ func toTypescriptType(firetype firemodel.SchemaFieldType) string {
 			return "any[]"
 		}
 	case *firemodel.Struct:
-		return interfaceName(firetype.T.Name)
+		return typescriptName(firetype.T.Package, interfaceName(firetype.T.Name))
+	case *firemodel.Union:
+		return typescriptName(firetype.T.Package, firetype.T.Name)
 	case *firemodel.File:
 		return "IFile"
 	case *firemodel.Map:
+		if firetype.K != nil {
+			return fmt.Sprintf("{ [key in %s]?: %s; }", toTypescriptType(firetype.K), toTypescriptType(firetype.T))
+		}
 		if firetype.T != nil {
 			return fmt.Sprintf("{ [key: string]: %s; }", toTypescriptType(firetype.T))
 		} else {
@@ -151,7 +397,7 @@ const (
 import { firestore } from 'firebase';
 
 // tslint:disable-next-line:no-namespace
-export namespace {{.Options | getSchemaOption "ts" "namespace" "firemodel"}} {
+export namespace {{.Schema.Options | getSchemaOption "ts" "namespace" "firemodel"}} {
   type URL = string;
 
   export interface IFile {
@@ -160,35 +406,59 @@ export namespace {{.Options | getSchemaOption "ts" "namespace" "firemodel"}} {
     name: string;
   }
 
+  {{- .Declarations}}
+  {{- range .Packages}}
+
+  export namespace {{.Name}} {
+  {{- .Declarations}}
+  }
+  {{- end}}
+}
+`
+	declarations = `
+  {{- range .Aliases -}}
+  {{- template "alias" .}}
+  {{- end}}
   {{- range .Enums -}}
   {{- template "enum" .}}
   {{- end}}
   {{- range .Structs -}}
   {{- template "struct" .}}
   {{- end}}
+  {{- range .Unions -}}
+  {{- template "union" .}}
+  {{- end}}
   {{- range .Models -}}
   {{- template "model" .}}
-  {{- end}}
-}
-`
+  {{- end}}`
+
 	model = `
   {{- if .Comment}}
 
   /** {{.Comment}} */
   {{- end}}
-  export interface {{.Name | interfaceName | ToCamel}} {
+  export interface {{.Name | interfaceName | ToCamel}}{{extends .Extends}} {
     {{- range .Collections}}
     {{- if .Comment}}
     /** {{.Comment}} */
     {{- end}}
-    {{.Name | ToLowerCamel}}: firestore.CollectionReference<{{.Type.Name | interfaceName | ToCamel}}>;
+    {{.Name | ToLowerCamel}}: firestore.CollectionReference<{{.Type.Name | interfaceName | ToCamel | typescriptName .Type.Package}}>;
     {{- end}}
 
     {{- range .Fields}}
-    {{- if .Comment}}
+    {{- if not .Origin}}
+    {{- if and .Comment .Deprecated}}
+    /**
+     * {{.Comment}}
+     * @deprecated
+     */
+    {{- else if .Comment}}
     /** {{.Comment}} */
+    {{- else if .Deprecated}}
+    /** @deprecated */
+    {{- end}}
+    {{.WireName}}{{if not .IsRequired}}?{{end}}: {{fieldType .}}{{if .Nullable}} | null{{end}};
     {{- end}}
-    {{.Name | ToLowerCamel -}}?: {{toTypescriptType .Type}};
     {{- end}}
     {{- if .Options | getModelOption "firestore" "autotimestamp" false}}
 
@@ -197,21 +467,76 @@ export namespace {{.Options | getSchemaOption "ts" "namespace" "firemodel"}} {
     /** Record update timestamp. */
     updatedAt?: firestore.Timestamp;
     {{- end}}
-  }`
+  }
+  {{- template "factory" .}}
+  {{- template "validator" .}}`
 
 	structTpl = `
   {{- if .Comment}}
 
   /** {{.Comment}} */
   {{- end}}
-  export interface {{.Name | interfaceName | ToCamel}} {
+  export interface {{.Name | interfaceName | ToCamel}}{{extends .Extends}} {
     {{- range .Fields}}
-    {{- if .Comment}}
+    {{- if not .Origin}}
+    {{- if and .Comment .Deprecated}}
+    /**
+     * {{.Comment}}
+     * @deprecated
+     */
+    {{- else if .Comment}}
     /** {{.Comment}} */
+    {{- else if .Deprecated}}
+    /** @deprecated */
+    {{- end}}
+    {{.WireName}}{{if not .IsRequired}}?{{end}}: {{fieldType .}}{{if .Nullable}} | null{{end}};
     {{- end}}
-    {{.Name | ToLowerCamel -}}?: {{toTypescriptType .Type}};
     {{- end}}
-  }`
+  }
+  {{- template "factory" .}}
+  {{- template "validator" .}}`
+
+	union = `
+  {{- if .Comment}}
+
+  /** {{.Comment}} */
+  {{- end}}
+  export type {{.Name | ToCamel}} =
+    {{- range .Members}}
+    | { {{$.Discriminator}}: {{literal .WireName}}; {{.WireName}}: {{toTypescriptType .Type}}; }
+    {{- end}};`
+
+	alias = `
+  {{- if .Comment}}
+
+  /** {{.Comment}} */
+  {{- end}}
+  export type {{.Name | ToCamel}} = {{toTypescriptType .Type}} & { readonly __brand: {{literal (typescriptName .Package .Name)}} };`
+
+	factory = `
+  {{- if hasDefaults .Fields}}
+
+  /** Returns a new {{.Name | interfaceName | ToCamel}} with default values. */
+  export function new{{.Name | ToCamel}}(): Partial<{{.Name | interfaceName | ToCamel}}> {
+    return {
+      {{- range .Fields}}
+      {{- if hasDefault .}}
+      {{.WireName}}: {{typescriptValue .}},
+      {{- end}}
+      {{- end}}
+    };
+  }
+  {{- end}}`
+
+	validator = `
+  {{- if hasValidation .Fields}}
+
+  /** Returns a description of the first validation constraint that m violates, if any. */
+  export function validate{{.Name | ToCamel}}(m: {{.Name | interfaceName | ToCamel}}): string | undefined {
+    {{- validations .Fields}}
+    return undefined;
+  }
+  {{- end}}`
 
 	enum = `
   {{- if .Comment}}
@@ -220,12 +545,36 @@ export namespace {{.Options | getSchemaOption "ts" "namespace" "firemodel"}} {
   {{- end}}
   export enum {{.Name | ToCamel}} {
     {{- range .Values}}
-    {{- if .Comment}}
+    {{- if and .Comment .Deprecated}}
+    /**
+     * {{.Comment}}
+     * @deprecated
+     */
+    {{- else if .Comment}}
     /** {{.Comment}} */
+    {{- else if .Deprecated}}
+    /** @deprecated */
     {{- end}}
-    {{.Name}} = '{{.Name | ToScreamingSnake}}',
+    {{.Name}} = {{literal .WireValue}},
     {{- end}}
-  }`
+  }
+  {{- if hasAliases .}}
+
+  /** Returns the {{.Name | ToCamel}} stored as value, or one of its aliases. */
+  export function parse{{.Name | ToCamel}}(value: {{if .IsIntegerBacked}}number{{else}}string{{end}}): {{.Name | ToCamel}} | undefined {
+    switch (value) {
+      {{- range .Values}}
+      case {{literal .WireValue}}:
+      {{- range .Aliases}}
+      case {{literal .}}:
+      {{- end}}
+        return {{$.Name | ToCamel}}.{{.Name}};
+      {{- end}}
+      default:
+        return undefined;
+    }
+  }
+  {{- end}}`
 
 	definitions = `import * as FIREBASE from 'firebase';
 
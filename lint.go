@@ -0,0 +1,133 @@
+package firemodel
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// maxFirestoreDepth is the maximum depth of maps and arrays in a Firestore document.
+const maxFirestoreDepth = 20
+
+// firestoreReservedPattern matches the field names and IDs that Firestore reserves.
+var firestoreReservedPattern = regexp.MustCompile("^__.*__$")
+
+// lint checks the compiled schema against the limits of Firestore, which the schema language
+// itself doesn't rule out.
+func (c *configSchemaCompiler) lint(schema *Schema) {
+	for _, model := range schema.Models {
+		c.lintFields(schema, model.Fields)
+		c.lintReservedIDs(model)
+		for _, field := range model.Fields {
+			if depth := c.depth(schema, field.Type, map[*SchemaStruct]bool{}); depth > maxFirestoreDepth {
+				c.warnAt(field.Pos, "field %s of %s nests maps and arrays %d levels deep (Firestore allows at most %d)", field.Name, model.Name, depth, maxFirestoreDepth)
+			}
+		}
+	}
+	for _, structType := range schema.Structs {
+		c.lintFields(schema, structType.Fields)
+	}
+	for _, union := range schema.Unions {
+		if firestoreReservedPattern.MatchString(union.Discriminator) {
+			c.errorAt(union.Pos, "union %s is discriminated by %s, which Firestore reserves (keys matching __.*__)", union.Name, union.Discriminator)
+		}
+		c.lintFields(schema, union.Members)
+	}
+}
+
+// lintFields checks the fields of a model, struct or union.
+func (c *configSchemaCompiler) lintFields(schema *Schema, fields []*SchemaField) {
+	for _, field := range fields {
+		if field.Origin != nil {
+			continue // checked in the struct it is inherited from
+		}
+		if firestoreReservedPattern.MatchString(field.WireName) {
+			c.errorAt(field.Pos, "field %s is stored as %s, which Firestore reserves (keys matching __.*__)", field.Name, field.WireName)
+		}
+		if hasNestedArray(field.Type) {
+			c.errorAt(field.Pos, "field %s has an array directly inside an array, which Firestore doesn't support (wrap the inner array in a struct)", field.Name)
+		}
+	}
+}
+
+// lintReservedIDs checks the constant collection IDs of the path of model.
+func (c *configSchemaCompiler) lintReservedIDs(model *SchemaModel) {
+	ids := strings.Split(model.Options.Get("firestore")["path"], "/")
+	if name, ok := model.Options.Get("firestore")["model_name"]; ok {
+		ids = append(ids, name)
+	}
+	for _, id := range ids {
+		if firestoreReservedPattern.MatchString(id) {
+			c.errorAt(model.Pos, "model %s is stored in %s, which Firestore reserves (IDs matching __.*__)", model.Name, id)
+		}
+	}
+}
+
+func hasNestedArray(fieldType SchemaFieldType) bool {
+	switch fieldType := fieldType.(type) {
+	case *Array:
+		if _, ok := fieldType.T.(*Array); ok {
+			return true
+		}
+		return hasNestedArray(fieldType.T)
+	case *Map:
+		return hasNestedArray(fieldType.T)
+	default:
+		return false
+	}
+}
+
+// depth returns the number of maps and arrays that values of fieldType may be nested in, counting
+// fieldType itself. Structs that contain themselves are only followed once.
+func (c *configSchemaCompiler) depth(schema *Schema, fieldType SchemaFieldType, seen map[*SchemaStruct]bool) int {
+	fieldsDepth := func(fields []*SchemaField) (max int) {
+		for _, field := range fields {
+			if depth := c.depth(schema, field.Type, seen); depth > max {
+				max = depth
+			}
+		}
+		return
+	}
+	switch fieldType := fieldType.(type) {
+	case *Array:
+		return 1 + c.depth(schema, fieldType.T, seen)
+	case *Map:
+		return 1 + c.depth(schema, fieldType.T, seen)
+	case *File:
+		return 1
+	case *Struct:
+		structType := schema.Struct(fieldType.T.Package, fieldType.T.Name)
+		if structType == nil || seen[structType] {
+			return 1
+		}
+		seen[structType] = true
+		defer delete(seen, structType)
+		return 1 + fieldsDepth(structType.Fields)
+	case *Union:
+		union := schema.Union(fieldType.T.Package, fieldType.T.Name)
+		if union == nil {
+			return 1
+		}
+		return 1 + fieldsDepth(union.Members)
+	default:
+		return 0
+	}
+}
+
+// errorAt records an error at pos, which is the position of a compiled declaration.
+func (c *configSchemaCompiler) errorAt(pos Position, format string, args ...interface{}) {
+	c.diagnostics = append(c.diagnostics, &Diagnostic{
+		Pos:      pos,
+		Severity: SeverityError,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// warnAt records a warning at pos, which is the position of a compiled declaration.
+func (c *configSchemaCompiler) warnAt(pos Position, format string, args ...interface{}) {
+	c.diagnostics = append(c.diagnostics, &Diagnostic{
+		Pos:      pos,
+		Severity: SeverityWarning,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
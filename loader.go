@@ -0,0 +1,156 @@
+package firemodel
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alecthomas/participle/lexer"
+	"github.com/pkg/errors"
+	"github.com/visor-tax/firemodel/internal/ast"
+)
+
+// schemaLoader parses schema files and follows their imports.
+//
+// Every file is parsed at most once, no matter how many times it is imported. Files are kept in the
+// order they are first encountered: an importing file comes before the files it imports.
+type schemaLoader struct {
+	files []*ast.AST
+	// paths are the paths of the files that were read, in the order they were read.
+	paths       []string
+	loaded      map[string]bool
+	stack       []string
+	diagnostics Diagnostics
+}
+
+func newSchemaLoader() *schemaLoader {
+	return &schemaLoader{
+		loaded: map[string]bool{},
+	}
+}
+
+func (l *schemaLoader) errorf(pos Position, format string, args ...interface{}) {
+	l.diagnostics = append(l.diagnostics, &Diagnostic{
+		Pos:      pos,
+		Severity: SeverityError,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// loadReader loads the schema read from r. Imports are resolved relative to r's file name, when r
+// is a file, and relative to the working directory otherwise.
+func (l *schemaLoader) loadReader(r io.Reader) {
+	name := lexer.NameOfReader(r)
+	if name != "" {
+		abs, err := filepath.Abs(name)
+		if err == nil && l.loaded[abs] {
+			return
+		}
+		l.loaded[abs] = true
+		l.paths = append(l.paths, name)
+	}
+
+	tree, err := parseAST(r)
+	if diags, ok := err.(Diagnostics); ok {
+		l.diagnostics = append(l.diagnostics, diags...)
+		return
+	} else if err != nil {
+		l.errorf(Position{Filename: name}, "%s", err)
+		return
+	}
+	l.loadImports(name, tree)
+}
+
+// loadFile loads the schema file at path. from is the import statement that refers to the file, or
+// nil if the file was requested directly.
+func (l *schemaLoader) loadFile(path string, from *ast.ASTImport) {
+	pos := Position{Filename: path}
+	if from != nil {
+		pos = positionOf(from.Pos)
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		l.errorf(pos, "%s", err)
+		return
+	}
+	for idx, importing := range l.stack {
+		if importing == abs {
+			cycle := append(l.displayStack(idx), path)
+			l.errorf(pos, "import cycle: %s", strings.Join(cycle, " -> "))
+			return
+		}
+	}
+	if l.loaded[abs] {
+		return
+	}
+	l.loaded[abs] = true
+	l.paths = append(l.paths, path)
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) && from != nil {
+		l.errorf(pos, "can't import %q: %s does not exist", from.Path, path)
+		return
+	} else if err != nil {
+		l.errorf(pos, "%s", err)
+		return
+	}
+	defer f.Close()
+
+	tree, err := parseAST(f)
+	if diags, ok := err.(Diagnostics); ok {
+		l.diagnostics = append(l.diagnostics, diags...)
+		return
+	} else if err != nil {
+		l.errorf(pos, "%s", err)
+		return
+	}
+	l.loadImports(path, tree)
+}
+
+func (l *schemaLoader) loadImports(path string, tree *ast.AST) {
+	l.files = append(l.files, tree)
+
+	if abs, err := filepath.Abs(path); path != "" && err == nil {
+		l.stack = append(l.stack, abs)
+		defer func() { l.stack = l.stack[:len(l.stack)-1] }()
+	}
+
+	dir := filepath.Dir(path)
+	for _, element := range tree.Types {
+		if element.Import == nil {
+			continue
+		}
+		l.loadFile(filepath.Join(dir, element.Import.Path), element.Import)
+	}
+}
+
+// displayStack returns the import chain starting at idx, relative to the working directory when
+// possible.
+func (l *schemaLoader) displayStack(idx int) (out []string) {
+	wd, _ := os.Getwd()
+	for _, abs := range l.stack[idx:] {
+		if rel, err := filepath.Rel(wd, abs); err == nil {
+			out = append(out, rel)
+		} else {
+			out = append(out, abs)
+		}
+	}
+	return
+}
+
+func parseAST(r io.Reader) (*ast.AST, error) {
+	tree, err := ast.ParseSchema(r)
+	if lexErr, ok := errors.Cause(err).(*lexer.Error); ok {
+		return nil, Diagnostics{{
+			Pos:      positionOf(lexErr.Pos),
+			Severity: SeverityError,
+			Message:  lexErr.Message,
+		}}
+	} else if err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
@@ -0,0 +1,156 @@
+package firemodel
+
+import (
+	"fmt"
+
+	"github.com/iancoleman/strcase"
+	"github.com/visor-tax/firemodel/internal/ast"
+)
+
+// generatedNames are the identifiers that are declared once in every generated Go package.
+var generatedNames = map[string]bool{"Client": true, "NewClient": true}
+
+// checkFieldName reports field if another field in seen has the same name once names are
+// normalized, e.g. fooBar and foo_bar, and otherwise adds it to seen. It returns false if the field
+// collides.
+func (c *configSchemaCompiler) checkFieldName(seen map[string]*ast.ASTField, field *ast.ASTField) bool {
+	name := strcase.ToCamel(strcase.ToSnake(field.Name))
+	if other, ok := seen[name]; ok {
+		c.errorf(field.Pos, "fields %s and %s are both named %s (declared at %s)", other.Name, field.Name, name, positionOf(other.Pos))
+		return false
+	}
+	seen[name] = field
+	return true
+}
+
+// typeDecl is a top-level declaration whose name is a type.
+type typeDecl struct {
+	kind string
+	name string
+	pos  Position
+}
+
+func (d *typeDecl) String() string {
+	return fmt.Sprintf("%s %s", d.kind, d.name)
+}
+
+// checkNames reports types whose names collide once they are normalized, either with each other or
+// with the identifiers that the generated code declares for them, and fields that collide with
+// the members generated alongside them. The generated code wouldn't compile otherwise.
+func (c *configSchemaCompiler) checkNames(schema *Schema) {
+	decls := map[string]map[string]*typeDecl{}
+	for _, v := range c.elements() {
+		var decl *typeDecl
+		switch {
+		case v.Model != nil && !v.Model.Identifier.IsReserved():
+			decl = &typeDecl{"model", string(v.Model.Identifier), positionOf(v.Model.Pos)}
+		case v.Struct != nil && !v.Struct.Identifier.IsReserved():
+			decl = &typeDecl{"struct", string(v.Struct.Identifier), positionOf(v.Struct.Pos)}
+		case v.Enum != nil && !v.Enum.Identifier.IsReserved():
+			decl = &typeDecl{"enum", string(v.Enum.Identifier), positionOf(v.Enum.Pos)}
+		case v.Union != nil && !v.Union.Identifier.IsReserved():
+			decl = &typeDecl{"union", string(v.Union.Identifier), positionOf(v.Union.Pos)}
+		case v.Alias != nil && !v.Alias.Identifier.IsReserved():
+			decl = &typeDecl{"type", string(v.Alias.Identifier), positionOf(v.Alias.Pos)}
+		default:
+			continue
+		}
+
+		pkg, name := c.packages[v], strcase.ToCamel(decl.name)
+		if decls[pkg] == nil {
+			decls[pkg] = map[string]*typeDecl{}
+		}
+		if other, ok := decls[pkg][name]; ok {
+			c.errorAt(decl.pos, "%s collides with %s (declared at %s)", decl, other, other.pos)
+			continue
+		}
+		if generatedNames[name] {
+			c.errorAt(decl.pos, "%s collides with %s, which is generated for every package", decl, name)
+			continue
+		}
+		decls[pkg][name] = decl
+	}
+
+	generated := func(pkg string, name string, owner string) {
+		if decl, ok := decls[pkg][name]; ok {
+			c.errorAt(decl.pos, "%s collides with %s, which is generated for %s", decl, name, owner)
+		}
+	}
+	for _, model := range schema.Models {
+		owner := "model " + model.Name
+		if format, _, _ := model.Options.GetFirestorePath(); format != "" {
+			for _, suffix := range []string{"Path", "RegexPath", "RegexNamedPath", "PathStruct", "PathToStruct", "StructToPath", "Wrapper", "FromSnapshot"} {
+				generated(model.Package, model.Name+suffix, owner)
+			}
+		}
+		if hasDefaults(model.Fields) {
+			generated(model.Package, "New"+model.Name, owner)
+		}
+		c.checkMemberNames(schema, owner, model.Extends, model.Fields)
+		if model.Options.GetAutoTimestamp() {
+			for _, field := range model.Fields {
+				if field.Origin != nil {
+					continue
+				}
+				for _, timestamp := range []string{"created_at", "updated_at"} {
+					if field.Name == timestamp || field.WireName == strcase.ToLowerCamel(timestamp) {
+						c.errorAt(field.Pos, "field %s collides with the %s field that firestore.autotimestamp adds to %s", field.Name, timestamp, owner)
+					}
+				}
+			}
+		}
+	}
+	for _, structType := range schema.Structs {
+		owner := "struct " + structType.Name
+		if hasDefaults(structType.Fields) {
+			generated(structType.Package, "New"+structType.Name, owner)
+		}
+		c.checkMemberNames(schema, owner, structType.Extends, structType.Fields)
+	}
+	for _, union := range schema.Unions {
+		owner := "union " + union.Name
+		generated(union.Package, "New"+union.Name, owner)
+		generated(union.Package, union.Name+"Member", owner)
+		members := map[string]string{
+			"Value":                              "the generated Value method",
+			"Set":                                "the generated Set method",
+			strcase.ToCamel(union.Discriminator): fmt.Sprintf("the discriminator %s", union.Discriminator),
+		}
+		for _, member := range union.Members {
+			if other, ok := members[strcase.ToCamel(member.Name)]; ok {
+				c.errorAt(member.Pos, "member %s of %s collides with %s", member.Name, owner, other)
+			}
+		}
+	}
+}
+
+// checkMemberNames reports fields of a model or struct that collide with the structs it extends,
+// which are embedded in the generated code, or with its generated Validate method.
+func (c *configSchemaCompiler) checkMemberNames(schema *Schema, owner string, extends []*SchemaStruct, fields []*SchemaField) {
+	members := map[string]string{}
+	for _, parent := range extends {
+		members[parent.Name] = fmt.Sprintf("the extended struct %s", parent.Name)
+	}
+	if schema.HasValidation(fields) {
+		members["Validate"] = "the generated Validate method"
+	}
+	for _, field := range fields {
+		if field.Origin != nil {
+			continue
+		}
+		if other, ok := members[strcase.ToCamel(field.Name)]; ok {
+			c.errorAt(field.Pos, "field %s of %s collides with %s", field.Name, owner, other)
+		}
+	}
+}
+
+// hasDefaults returns true if any of fields has a default value, in which case a constructor is
+// generated for its type.
+func hasDefaults(fields []*SchemaField) bool {
+	for _, field := range fields {
+		if field.Default != nil {
+			return true
+		}
+	}
+	return false
+}
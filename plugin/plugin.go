@@ -0,0 +1,173 @@
+// Package plugin generates code with external executables, so that languages can be added without
+// rebuilding firemodel.
+//
+// The code for a language foo that has no registered modeler is generated by the executable
+// firemodel-gen-foo on PATH. It is run once per output directory with a JSON encoded Request on
+// stdin, and writes a JSON encoded Response to stdout. The files of the response are written to
+// the output directory like the files of any other modeler. Anything the plugin writes to stderr
+// is passed through, and it fails by exiting with a non-zero status or setting Response.Error.
+//
+// Plugins written in Go can use Serve to implement the protocol.
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/visor-tax/firemodel"
+	"github.com/visor-tax/firemodel/ir"
+)
+
+// Request is the input of a plugin.
+type Request struct {
+	// Language is the name of the language, e.g. "kotlin" for firemodel-gen-kotlin.
+	Language string `json:"language"`
+	// Parameters are the parameters of the plugin, set with --<language>_opt key=value.
+	Parameters map[string]string `json:"parameters"`
+	// Schema is the schema to generate code for. Its options include the options of the language
+	// set in the project file.
+	Schema *ir.Schema `json:"schema"`
+}
+
+// Response is the output of a plugin.
+type Response struct {
+	// Files are the files to write, replacing the files of the previous run.
+	Files []*File `json:"files"`
+	// Error is set if the plugin failed, in which case nothing is written.
+	Error string `json:"error,omitempty"`
+}
+
+// File is a generated file.
+type File struct {
+	// Name is the path of the file, relative to the output directory. It may not refer to files
+	// outside of it.
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// Modeler is a firemodel.Modeler that generates code with a plugin.
+type Modeler struct {
+	// Language is the name of the language.
+	Language string
+	// Path is the path of the plugin executable.
+	Path string
+	// Parameters are passed to the plugin as Request.Parameters.
+	Parameters map[string]string
+}
+
+// Lookup returns the Modeler for language, using the plugin for it on PATH.
+func Lookup(language string) (*Modeler, error) {
+	path, err := exec.LookPath(firemodel.PluginPrefix + language)
+	if err != nil {
+		return nil, errors.Errorf("firemodel: unknown language %s: no modeler is registered for it, and %s%s is not on PATH", language, firemodel.PluginPrefix, language)
+	}
+	return &Modeler{Language: language, Path: path}, nil
+}
+
+func (m *Modeler) Model(schema *firemodel.Schema, sourceCoder firemodel.SourceCoder) error {
+	params := m.Parameters
+	if params == nil {
+		params = map[string]string{}
+	}
+	request, err := json.Marshal(&Request{
+		Language:   m.Language,
+		Parameters: params,
+		Schema:     ir.FromSchema(schema),
+	})
+	if err != nil {
+		return err
+	}
+	stdout := &bytes.Buffer{}
+	cmd := exec.Command(m.Path)
+	cmd.Stdin = bytes.NewReader(request)
+	cmd.Stdout = stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return errors.Wrapf(err, "firemodel: plugin %s failed", m.Path)
+	}
+
+	response := &Response{}
+	if err := json.Unmarshal(stdout.Bytes(), response); err != nil {
+		return errors.Wrapf(err, "firemodel: plugin %s returned an invalid response", m.Path)
+	}
+	if response.Error != "" {
+		return errors.Errorf("firemodel: plugin %s failed: %s", m.Path, response.Error)
+	}
+	for _, file := range response.Files {
+		if !isLocal(file.Name) {
+			return errors.Errorf("firemodel: plugin %s returned a file outside of the output directory: %q", m.Path, file.Name)
+		}
+	}
+	for _, file := range response.Files {
+		w, err := sourceCoder.NewFile(filepath.Clean(file.Name))
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, file.Content); err != nil {
+			w.Close()
+			return err
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isLocal returns true if name is a relative path that doesn't leave the directory it is relative
+// to.
+func isLocal(name string) bool {
+	if name == "" || filepath.IsAbs(name) {
+		return false
+	}
+	clean := filepath.Clean(name)
+	return clean != "." && clean != ".." && !strings.HasPrefix(clean, ".."+string(filepath.Separator))
+}
+
+// Serve implements the plugin protocol with generate: it reads the request from stdin, and writes
+// the files returned by generate, or its error, to stdout. It exits with a non-zero status if the
+// request or response can't be read or written.
+func Serve(generate func(request *Request) ([]*File, error)) {
+	request := &Request{}
+	if err := json.NewDecoder(os.Stdin).Decode(request); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: invalid request: %v\n", filepath.Base(os.Args[0]), err)
+		os.Exit(1)
+	}
+	response := &Response{Files: []*File{}}
+	if files, err := generate(request); err != nil {
+		response.Error = err.Error()
+	} else if files != nil {
+		response.Files = files
+	}
+	if err := json.NewEncoder(os.Stdout).Encode(response); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", filepath.Base(os.Args[0]), err)
+		os.Exit(1)
+	}
+}
+
+// Installed returns the languages of the plugins on PATH, sorted.
+func Installed() []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		matches, _ := filepath.Glob(filepath.Join(dir, firemodel.PluginPrefix+"*"))
+		for _, match := range matches {
+			language := strings.TrimPrefix(filepath.Base(match), firemodel.PluginPrefix)
+			if _, err := exec.LookPath(match); err != nil || seen[language] {
+				continue
+			}
+			seen[language] = true
+			out = append(out, language)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
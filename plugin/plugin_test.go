@@ -0,0 +1,73 @@
+package plugin_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/visor-tax/firemodel"
+	"github.com/visor-tax/firemodel/internal/memwriter"
+	"github.com/visor-tax/firemodel/plugin"
+	"gotest.tools/assert"
+)
+
+// TestMain runs the test binary as a plugin if FIREMODEL_TEST_PLUGIN is set. The plugin writes a
+// file listing the models of the schema, or returns the files named by the "file" parameter.
+func TestMain(m *testing.M) {
+	if os.Getenv("FIREMODEL_TEST_PLUGIN") == "" {
+		os.Exit(m.Run())
+	}
+	plugin.Serve(func(request *plugin.Request) ([]*plugin.File, error) {
+		if request.Parameters["fail"] != "" {
+			return nil, errors.New(request.Parameters["fail"])
+		}
+		if name, ok := request.Parameters["file"]; ok {
+			return []*plugin.File{{Name: name}}, nil
+		}
+		var models []string
+		for _, model := range request.Schema.Models {
+			models = append(models, model.Name)
+		}
+		return []*plugin.File{{
+			Name:    filepath.Join("models", request.Language+".txt"),
+			Content: fmt.Sprintf("version %d\n%s\n", request.Schema.Version, strings.Join(models, "\n")),
+		}}, nil
+	})
+	os.Exit(0)
+}
+
+func TestModeler(t *testing.T) {
+	os.Setenv("FIREMODEL_TEST_PLUGIN", "true")
+	defer os.Unsetenv("FIREMODEL_TEST_PLUGIN")
+
+	schema, err := firemodel.ParseSchemaFiles("../testfixtures/schema/simple.firemodel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := func(params map[string]string) (*memwriter.MemWriter, error) {
+		w := memwriter.New("out")
+		modeler := &plugin.Modeler{Language: "test", Path: os.Args[0], Parameters: params}
+		return w, modeler.Model(schema, w)
+	}
+
+	t.Run("files", func(t *testing.T) {
+		w, err := run(nil)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, w.Files(), map[string][]byte{
+			filepath.Join("models", "test.txt"): []byte("version 1\nSimpleModel\n"),
+		})
+	})
+	t.Run("error", func(t *testing.T) {
+		_, err := run(map[string]string{"fail": "no kotlin"})
+		assert.Error(t, err, "firemodel: plugin "+os.Args[0]+" failed: no kotlin")
+	})
+	t.Run("outside output directory", func(t *testing.T) {
+		for _, name := range []string{"", "/etc/passwd", "..", "../x", "a/../../x"} {
+			_, err := run(map[string]string{"file": name})
+			assert.ErrorContains(t, err, "returned a file outside of the output directory")
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package firemodel
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// ProjectFileName is the name of the project file that is looked up from the working directory.
+const ProjectFileName = "firemodel.yaml"
+
+// Project is the configuration of the compile command, read from a project file, e.g.
+//
+//	schemas:
+//	  - schema/*.firemodel
+//	wipe: true
+//	languages:
+//	  go:
+//	    out: gen/go
+//	    options:
+//	      package: models
+//	  ts:
+//	    out: gen/ts
+//
+// Paths are relative to the directory of the project file.
+type Project struct {
+	// Schemas are the globs of the schema files to compile.
+	Schemas []string `yaml:"schemas"`
+	// Wipe is true if the files in the output directories that aren't generated may be removed.
+	Wipe bool `yaml:"wipe"`
+	// Languages are the languages to generate, keyed by the name of their modeler or plugin.
+	Languages map[string]*ProjectLanguage `yaml:"languages"`
+	// Breaking are the severities of the rules of the breaking command, keyed by rule name, e.g.
+	// {"field_removed": "error"}.
+	Breaking map[string]string `yaml:"breaking"`
+}
+
+// ProjectLanguage configures the output of a single language.
+type ProjectLanguage struct {
+	// Output is the output directory.
+	Output string `yaml:"out"`
+	// Options override the options of the language set in the schema, e.g. package overrides
+	// `option go.package = "...";`.
+	Options map[string]string `yaml:"options"`
+}
+
+// LoadProject reads the project file at path.
+func LoadProject(path string) (*Project, error) {
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	project := &Project{}
+	if err := yaml.UnmarshalStrict(src, project); err != nil {
+		return nil, errors.Wrapf(err, "firemodel: invalid project file %s", path)
+	}
+
+	dir := filepath.Dir(path)
+	for idx, schema := range project.Schemas {
+		project.Schemas[idx] = filepath.Join(dir, schema)
+	}
+	for name, language := range project.Languages {
+		if _, ok := registeredModelers[name]; !ok {
+			if _, err := exec.LookPath(PluginPrefix + name); err != nil {
+				return nil, errors.Errorf("firemodel: invalid project file %s: unknown language %s", path, name)
+			}
+		}
+		if language == nil || language.Output == "" {
+			return nil, errors.Errorf("firemodel: invalid project file %s: no output directory for %s", path, name)
+		}
+		language.Output = filepath.Join(dir, language.Output)
+	}
+	return project, nil
+}
+
+// FindProject loads the project file in dir, or in the closest of its parents that has one. It
+// returns nil if there is none. If dir is relative, so are the paths of the project.
+func FindProject(dir string) (*Project, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		path := filepath.Join(abs, ProjectFileName)
+		if _, err := os.Stat(path); err == nil {
+			// Keep paths relative to the working directory if dir is.
+			if wd, err := os.Getwd(); err == nil && !filepath.IsAbs(dir) {
+				if rel, err := filepath.Rel(wd, path); err == nil {
+					path = rel
+				}
+			}
+			return LoadProject(path)
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return nil, nil
+		}
+		abs = parent
+	}
+}
+
+// LanguageNames returns the names of the languages of the project, sorted.
+func (p *Project) LanguageNames() (out []string) {
+	for name := range p.Languages {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return
+}
+
+// ApplyOptions overrides the options of schema with the options of the languages of the project.
+func (p *Project) ApplyOptions(schema *Schema) {
+	for name, language := range p.Languages {
+		if len(language.Options) == 0 {
+			continue
+		}
+		if schema.Options == nil {
+			schema.Options = SchemaOptions{}
+		}
+		if schema.Options[name] == nil {
+			schema.Options[name] = map[string]string{}
+		}
+		for key, value := range language.Options {
+			schema.Options[name][key] = value
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package firemodel_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/visor-tax/firemodel"
+	"gotest.tools/assert"
+)
+
+func TestFindProject(t *testing.T) {
+	dir := filepath.Join("testfixtures", "project")
+	project, err := firemodel.FindProject(filepath.Join(dir, "nested"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.DeepEqual(t, project, &firemodel.Project{
+		Schemas: []string{filepath.Join("testfixtures", "schema", "simple.firemodel")},
+		Wipe:    true,
+		Languages: map[string]*firemodel.ProjectLanguage{
+			"go": {Output: filepath.Join(dir, "gen", "go"), Options: map[string]string{"package": "models"}},
+			"ts": {Output: filepath.Join(dir, "gen", "ts")},
+		},
+	})
+	assert.DeepEqual(t, project.LanguageNames(), []string{"go", "ts"})
+
+	schema, err := firemodel.ParseSchemaFiles(project.Schemas...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	project.ApplyOptions(schema)
+	assert.Equal(t, schema.Options.Get("go")["package"], "models")
+}
+
+func TestLoadProjectUnknownLanguage(t *testing.T) {
+	path := filepath.Join("testfixtures", "project", "unknown_language.yaml")
+	_, err := firemodel.LoadProject(path)
+	assert.Error(t, err, "firemodel: invalid project file "+path+": unknown language cobol")
+}
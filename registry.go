@@ -6,6 +6,10 @@ var (
 	registeredModelers = map[string]Modeler{}
 )
 
+// PluginPrefix is the prefix of the executables that generate the languages that have no
+// registered modeler, e.g. firemodel-gen-kotlin for --kotlin_out. See package plugin.
+const PluginPrefix = "firemodel-gen-"
+
 func RegisterModeler(name string, m Modeler) {
 	if _, ok := registeredModelers[name]; ok {
 		panic(errors.Errorf("firemodel: %s modeler already registered", name))
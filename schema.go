@@ -1,234 +1,904 @@
 package firemodel
 
 import (
+	"fmt"
 	"io"
+	"regexp"
+	"strconv"
+	"strings"
 
+	"github.com/alecthomas/participle/lexer"
 	"github.com/iancoleman/strcase"
 	"github.com/pkg/errors"
 	"github.com/visor-tax/firemodel/internal/ast"
 )
 
+// ParseSchema parses and compiles the schema read from r, along with any files it imports.
+//
+// Imports are resolved relative to r's file name when r is an *os.File, and relative to the working
+// directory otherwise. Problems with the schema are returned as Diagnostics.
 func ParseSchema(r io.Reader) (*Schema, error) {
-	tree, err := ast.ParseSchema(r)
+	loader := newSchemaLoader()
+	loader.loadReader(r)
+	return loader.compile()
+}
+
+// ParseSchemaFiles parses the schema files at paths, along with any files they import, and compiles
+// them into a single Schema.
+//
+// All of the files share a single namespace, so types may be referenced across files. A file that
+// is both listed in paths and imported is only parsed once. Problems with any of the files are
+// returned together as Diagnostics.
+func ParseSchemaFiles(paths ...string) (*Schema, error) {
+	loader := newSchemaLoader()
+	for _, path := range paths {
+		loader.loadFile(path, nil)
+	}
+	return loader.compile()
+}
+
+func (l *schemaLoader) compile() (*Schema, error) {
+	if len(l.diagnostics) > 0 {
+		l.diagnostics.sort()
+		return nil, l.diagnostics
+	}
+	schema, err := compileSchema(l.files...)
 	if err != nil {
 		return nil, err
 	}
-	compiler := &configSchemaCompiler{ast: tree}
-	config, err := compiler.compileConfig()
-	if err != nil {
-		return nil, errors.Wrap(err, "firemodel/schema")
+	schema.Files = l.paths
+	return schema, nil
+}
+
+func compileSchema(trees ...*ast.AST) (*Schema, error) {
+	compiler := &configSchemaCompiler{files: trees}
+	schema := compiler.compileConfig()
+	compiler.lint(schema)
+	compiler.diagnostics.sort()
+	if compiler.diagnostics.HasErrors() {
+		return nil, compiler.diagnostics
 	}
-	return config, nil
+	if len(compiler.diagnostics) > 0 {
+		schema.Warnings = compiler.diagnostics
+	}
+	return schema, nil
 }
 
 type configSchemaCompiler struct {
 	models  []*SchemaModel
 	structs []*SchemaStruct
 	enums   []*SchemaEnum
+	unions  []*SchemaUnion
+	aliases []*SchemaAlias
+	// enumDecls maps the enums above to their declarations, so that enum values can be referenced
+	// before the enums are compiled.
+	enumDecls map[*SchemaEnum]*ast.ASTEnum
+	// aliasDecls maps the aliases above to their declarations, and compiledAliases to the compiled
+	// aliases, which are compiled on first use so that aliases can refer to other aliases.
+	aliasDecls       map[*SchemaAlias]*ast.ASTAlias
+	compiledAliases  map[*SchemaAlias]*SchemaAlias
+	compilingAliases map[*SchemaAlias]bool
 
-	ast *ast.AST
+	files       []*ast.AST
+	packages    map[*ast.ASTElement]string
+	options     SchemaOptions
+	diagnostics Diagnostics
 }
 
-func (c *configSchemaCompiler) compileConfig() (*Schema, error) {
-	if err := c.precompileEnumTypes(); err != nil {
-		return nil, err
-	}
-	if err := c.precompileModelTypes(); err != nil {
-		return nil, err
+func (c *configSchemaCompiler) errorf(pos lexer.Position, format string, args ...interface{}) {
+	c.errorAt(positionOf(pos), format, args...)
+}
+
+// elements returns the top-level elements of every file being compiled, in order.
+func (c *configSchemaCompiler) elements() (out []*ast.ASTElement) {
+	for _, file := range c.files {
+		out = append(out, file.Types...)
 	}
-	if err := c.precompileStructTypes(); err != nil {
-		return nil, err
+	return
+}
+
+// joinComments joins the lines of a comment into the single line that is generated.
+func joinComments(comments []*ast.ASTComment) string {
+	lines := make([]string, len(comments))
+	for idx, comment := range comments {
+		lines[idx] = comment.Text
 	}
+	return strings.Join(lines, " ")
+}
 
-	return &Schema{
+func (c *configSchemaCompiler) compileConfig() *Schema {
+	c.precompilePackages()
+	c.precompileEnumTypes()
+	c.precompileModelTypes()
+	c.precompileStructTypes()
+	c.precompileUnionTypes()
+	c.precompileAliasTypes()
+	c.options = c.compileLanguageOptions()
+
+	schema := &Schema{
 		Models:  c.compileModels(),
 		Enums:   c.compileEnums(),
 		Structs: c.compileStructs(),
-		Options: c.compileLanguageOptions(),
-	}, nil
+		Unions:  c.compileUnions(),
+		Aliases: c.compileAliases(),
+		Options: c.options,
+	}
+	c.inheritFields(schema)
+	c.checkReservedFields(schema)
+	c.checkNames(schema)
+	c.checkCycles(schema)
+	return schema
 }
 
-func (c *configSchemaCompiler) precompileEnumTypes() error {
+// precompilePackages records the package of every top-level element. A file may declare at most
+// one package; files without a package declaration belong to the default package, "".
+func (c *configSchemaCompiler) precompilePackages() {
+	c.packages = map[*ast.ASTElement]string{}
+	for _, file := range c.files {
+		var pkg *ast.ASTPackage
+		for _, v := range file.Types {
+			if v.Package == nil {
+				continue
+			}
+			if pkg != nil {
+				c.errorf(v.Package.Pos, "package already declared as %s at %s", pkg.Name, pkg.Pos)
+				continue
+			}
+			if v.Package.Name.IsReserved() {
+				c.errorf(v.Package.Pos, "can't name package %s, %s is a reserved word.", v.Package.Name, v.Package.Name)
+				continue
+			}
+			pkg = v.Package
+		}
+		for _, v := range file.Types {
+			if pkg != nil {
+				c.packages[v] = string(pkg.Name)
+			} else {
+				c.packages[v] = ""
+			}
+		}
+	}
+}
+
+func (c *configSchemaCompiler) isPackage(pkg string) bool {
+	for _, declared := range c.packages {
+		if declared == pkg {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *configSchemaCompiler) precompileEnumTypes() {
 	c.enums = make([]*SchemaEnum, 0)
-	for _, v := range c.ast.Types {
+	c.enumDecls = map[*SchemaEnum]*ast.ASTEnum{}
+	for _, v := range c.elements() {
 		if v.Enum == nil {
 			continue
 		}
 
 		if v.Enum.Identifier.IsReserved() {
-			err := errors.Errorf("firemodel/schema: can't name enum %s, %s is a reserved word.", v.Enum.Identifier, v.Enum.Identifier)
-			return err
+			c.errorf(v.Enum.Pos, "can't name enum %s, %s is a reserved word.", v.Enum.Identifier, v.Enum.Identifier)
+			continue
 		}
 
-		c.enums = append(c.enums, &SchemaEnum{
-			Name: strcase.ToCamel(string(v.Enum.Identifier)),
-		})
+		enum := &SchemaEnum{
+			Name:    strcase.ToCamel(string(v.Enum.Identifier)),
+			Package: c.packages[v],
+			Backing: enumBacking(v.Enum),
+		}
+		c.enums = append(c.enums, enum)
+		c.enumDecls[enum] = v.Enum
 	}
-	return nil
 }
 
-func (c *configSchemaCompiler) precompileModelTypes() error {
+func (c *configSchemaCompiler) precompileModelTypes() {
 	c.models = make([]*SchemaModel, 0)
-	for _, v := range c.ast.Types {
+	for _, v := range c.elements() {
 		if v.Model == nil {
 			continue
 		}
 
 		if v.Model.Identifier.IsReserved() {
-			err := errors.Errorf("firemodel/schema: can't name model %s, %s is a reserved word.", v.Model.Identifier, v.Model.Identifier)
-			return err
+			c.errorf(v.Model.Pos, "can't name model %s, %s is a reserved word.", v.Model.Identifier, v.Model.Identifier)
+			continue
 		}
 
 		c.models = append(c.models, &SchemaModel{
-			Name: strcase.ToCamel(string(v.Model.Identifier)),
+			Name:    strcase.ToCamel(string(v.Model.Identifier)),
+			Package: c.packages[v],
 		})
 	}
-	return nil
 }
 
-func (c *configSchemaCompiler) precompileStructTypes() error {
+func (c *configSchemaCompiler) precompileStructTypes() {
 	c.structs = make([]*SchemaStruct, 0)
-	for _, v := range c.ast.Types {
+	for _, v := range c.elements() {
 		if v.Struct == nil {
 			continue
 		}
 
 		if v.Struct.Identifier.IsReserved() {
-			err := errors.Errorf("firemodel/schema: can't name struct %s, %s is a reserved word.", v.Struct.Identifier, v.Struct.Identifier)
-			return err
+			c.errorf(v.Struct.Pos, "can't name struct %s, %s is a reserved word.", v.Struct.Identifier, v.Struct.Identifier)
+			continue
 		}
 
 		c.structs = append(c.structs, &SchemaStruct{
-			Name: strcase.ToCamel(string(v.Struct.Identifier)),
+			Name:    strcase.ToCamel(string(v.Struct.Identifier)),
+			Package: c.packages[v],
 		})
 	}
-	return nil
 }
 
-func (c *configSchemaCompiler) compileModels() (out []*SchemaModel) {
-	for _, v := range c.ast.Types {
-		if v.Model == nil {
+func (c *configSchemaCompiler) precompileUnionTypes() {
+	c.unions = make([]*SchemaUnion, 0)
+	for _, v := range c.elements() {
+		if v.Union == nil {
 			continue
 		}
 
-		if v.Model.Identifier.IsReserved() {
-			err := errors.Errorf("firemodel/schema: can't name model %s, %s is a reserved word.", v.Model.Identifier, v.Model.Identifier)
-			panic(err)
+		if v.Union.Identifier.IsReserved() {
+			c.errorf(v.Union.Pos, "can't name union %s, %s is a reserved word.", v.Union.Identifier, v.Union.Identifier)
+			continue
+		}
+
+		c.unions = append(c.unions, &SchemaUnion{
+			Name:    strcase.ToCamel(string(v.Union.Identifier)),
+			Package: c.packages[v],
+		})
+	}
+}
+
+func (c *configSchemaCompiler) precompileAliasTypes() {
+	c.aliases = make([]*SchemaAlias, 0)
+	c.aliasDecls = map[*SchemaAlias]*ast.ASTAlias{}
+	c.compiledAliases = map[*SchemaAlias]*SchemaAlias{}
+	c.compilingAliases = map[*SchemaAlias]bool{}
+	for _, v := range c.elements() {
+		if v.Alias == nil {
+			continue
+		}
+
+		if v.Alias.Identifier.IsReserved() {
+			c.errorf(v.Alias.Pos, "can't name type %s, %s is a reserved word.", v.Alias.Identifier, v.Alias.Identifier)
+			continue
+		}
+
+		alias := &SchemaAlias{
+			Name:    strcase.ToCamel(string(v.Alias.Identifier)),
+			Package: c.packages[v],
+			Comment: joinComments(v.Comments),
+		}
+		c.aliases = append(c.aliases, alias)
+		c.aliasDecls[alias] = v.Alias
+	}
+}
+
+func (c *configSchemaCompiler) compileModels() (out []*SchemaModel) {
+	for _, v := range c.elements() {
+		if v.Model == nil || v.Model.Identifier.IsReserved() {
+			continue
 		}
 
+		pkg := c.packages[v]
+		options := c.compileModelOptions(v.Model.Elements)
+		var reserved []*ast.ASTReserved
+		for _, element := range v.Model.Elements {
+			if element.Reserved != nil {
+				reserved = append(reserved, element.Reserved)
+			}
+		}
 		out = append(out, &SchemaModel{
 			Name:        strcase.ToCamel(string(v.Model.Identifier)),
-			Comment:     v.Comment,
-			Fields:      c.compileModelFields(v.Model.Elements),
-			Collections: c.compileCollections(v.Model.Elements),
-			Options:     c.compileModelOptions(v.Model.Elements),
+			Package:     pkg,
+			Comment:     joinComments(v.Comments),
+			Extends:     c.compileExtends(pkg, v.Model.Extends),
+			Fields:      c.compileModelFields(pkg, c.modelNaming(options), v.Model.Elements),
+			Collections: c.compileCollections(pkg, v.Model.Elements),
+			Reserved:    c.compileReservedFields(reserved),
+			Options:     options,
+			Pos:         positionOf(v.Model.Pos),
 		})
 	}
 	return
 }
 
 func (c *configSchemaCompiler) compileStructs() (out []*SchemaStruct) {
-	for _, v := range c.ast.Types {
-		if v.Struct == nil {
+	for _, v := range c.elements() {
+		if v.Struct == nil || v.Struct.Identifier.IsReserved() {
 			continue
 		}
 
-		if v.Struct.Identifier.IsReserved() {
-			err := errors.Errorf("firemodel/schema: can't name struct %s, %s is a reserved word.", v.Struct.Identifier, v.Struct.Identifier)
-			panic(err)
+		pkg := c.packages[v]
+		var reserved []*ast.ASTReserved
+		for _, element := range v.Struct.Elements {
+			if element.Reserved != nil {
+				reserved = append(reserved, element.Reserved)
+			}
 		}
-
 		out = append(out, &SchemaStruct{
-			Name:    strcase.ToCamel(string(v.Struct.Identifier)),
-			Comment: v.Comment,
-			Fields:  c.compileStructFields(v.Struct.Elements),
+			Name:     strcase.ToCamel(string(v.Struct.Identifier)),
+			Package:  pkg,
+			Comment:  joinComments(v.Comments),
+			Extends:  c.compileExtends(pkg, v.Struct.Extends),
+			Fields:   c.compileStructFields(pkg, c.fieldNaming(), v.Struct.Elements),
+			Reserved: c.compileReservedFields(reserved),
+			Pos:      positionOf(v.Struct.Pos),
 		})
 	}
 	return
 }
 
-func (c *configSchemaCompiler) compileEnums() (out []*SchemaEnum) {
-	for _, v := range c.ast.Types {
-		if v.Enum == nil {
+func (c *configSchemaCompiler) compileUnions() (out []*SchemaUnion) {
+	for _, v := range c.elements() {
+		if v.Union == nil || v.Union.Identifier.IsReserved() {
 			continue
 		}
-		out = append(out, &SchemaEnum{
-			Name:    strcase.ToCamel(string(v.Enum.Identifier)),
-			Comment: v.Comment,
-			Values:  c.enumValuesToConfig(v.Enum.Values),
+
+		pkg := c.packages[v]
+		options := c.compileModelOptions(v.Union.Elements)
+		naming := c.modelNaming(options)
+		discriminator, ok := options.Get("firestore")["discriminator"]
+		if !ok {
+			discriminator = "type"
+		}
+		out = append(out, &SchemaUnion{
+			Name:          strcase.ToCamel(string(v.Union.Identifier)),
+			Package:       pkg,
+			Comment:       joinComments(v.Comments),
+			Discriminator: discriminator,
+			Members:       c.compileUnionMembers(pkg, naming, discriminator, v.Union),
+			Options:       options,
+			Pos:           positionOf(v.Union.Pos),
 		})
 	}
 	return
 }
 
+// compileUnionMembers returns the members of union. Every member must be a struct, and no two
+// members may have the same type, so that the member a value holds can be told apart by its type.
+// Members must be declared in the package of the union, since in Go they implement an interface
+// that only types of the same package can.
+func (c *configSchemaCompiler) compileUnionMembers(pkg string, naming FieldNaming, discriminator string, union *ast.ASTUnion) (out []*SchemaField) {
+	types := map[*SchemaStruct]*SchemaField{}
+	names := map[string]*ast.ASTField{}
+	members := 0
+	for _, element := range union.Elements {
+		if element.Field != nil {
+			members++
+		}
+	}
+	if members == 0 {
+		c.errorf(union.Pos, "union %s must have at least one member", union.Identifier)
+	}
+	for _, element := range union.Elements {
+		if element.Reserved != nil {
+			c.errorf(element.Reserved.Values[0].Pos, "can't reserve names in union %s", union.Identifier)
+		}
+		member := element.Field
+		if member == nil || !c.checkFieldName(names, member) {
+			continue
+		}
+		if member.Modifier != "" || member.Nullable || member.Default != nil {
+			c.errorf(member.Pos, "union member %s can't be required, optional, nullable or have a default value", member.Name)
+			continue
+		}
+		structType, ok := c.assertStructType(pkg, member.Type)
+		if !ok || member.Type.Generic != nil {
+			c.errorf(member.Type.Pos, "invalid type %s for union member %s (must be a struct type)", member.Type, member.Name)
+			continue
+		}
+		if structType.Package != pkg {
+			c.errorf(member.Type.Pos, "union member %s is of type %s, which must be declared in the package of union %s", member.Name, member.Type, union.Identifier)
+			continue
+		}
+		if other, ok := types[structType]; ok {
+			c.errorf(member.Pos, "union members %s and %s are both of type %s", other.Name, strcase.ToSnake(member.Name), member.Type)
+			continue
+		}
+		options := c.compileFieldOptions(member.Options)
+		field := &SchemaField{
+			Name:     strcase.ToSnake(member.Name),
+			WireName: c.wireName(member, naming, options),
+			Comment:  joinComments(member.Comments),
+			Type:     &Struct{T: structType},
+			Options:  options,
+			Pos:      positionOf(member.Pos),
+		}
+		if field.WireName == discriminator {
+			c.errorf(member.Pos, "union member %s is stored as %s, which is the discriminator of union %s", field.Name, field.WireName, union.Identifier)
+			continue
+		}
+		types[structType] = field
+		out = append(out, field)
+	}
+	c.checkWireNames(out)
+	return
+}
+
+func (c *configSchemaCompiler) compileAliases() (out []*SchemaAlias) {
+	for _, alias := range c.aliases {
+		if compiled := c.compileAlias(alias); compiled != nil {
+			out = append(out, compiled)
+		}
+	}
+	return
+}
+
+// compileAlias returns the compiled alias, compiling it if it was not compiled yet, or nil if it is
+// invalid.
+func (c *configSchemaCompiler) compileAlias(alias *SchemaAlias) (compiled *SchemaAlias) {
+	if compiled, ok := c.compiledAliases[alias]; ok {
+		return compiled
+	}
+	decl := c.aliasDecls[alias]
+	if c.compilingAliases[alias] {
+		c.errorf(decl.Pos, "type %s refers to itself", decl.Identifier)
+		return nil
+	}
+	c.compilingAliases[alias] = true
+	defer func() {
+		delete(c.compilingAliases, alias)
+		c.compiledAliases[alias] = compiled
+	}()
+
+	aliasType := c.compileFieldType(alias.Package, decl.Type)
+	if aliasType == nil {
+		return nil
+	}
+	switch aliasType.(type) {
+	case *Enum, *Struct, *Union, *File:
+		c.errorf(decl.Type.Pos, "can't alias %s (must be a primitive type)", decl.Type)
+		return nil
+	}
+	field := &ast.ASTField{Pos: decl.Pos, Name: string(decl.Identifier), Type: decl.Type, Options: decl.Options}
+	return &SchemaAlias{
+		Name:        alias.Name,
+		Package:     alias.Package,
+		Comment:     alias.Comment,
+		Type:        aliasType,
+		Constraints: append(c.aliasConstraints(c.assertAliasType(alias.Package, decl.Type)), c.compileConstraints("type "+alias.Name, field, aliasType)...),
+		Options:     c.compileFieldOptions(decl.Options),
+		Pos:         positionOf(decl.Pos),
+	}
+}
+
+// aliasConstraints returns a copy of the constraints of alias, or nil if alias is nil.
+func (c *configSchemaCompiler) aliasConstraints(alias *SchemaAlias) []*SchemaConstraint {
+	if alias == nil {
+		return nil
+	}
+	if compiled := c.compileAlias(alias); compiled != nil && len(compiled.Constraints) > 0 {
+		return append([]*SchemaConstraint{}, compiled.Constraints...)
+	}
+	return nil
+}
+
+// compileExtends returns the structs in the extends clause of a model or struct.
+func (c *configSchemaCompiler) compileExtends(pkg string, extends []*ast.ASTFieldType) (out []*SchemaStruct) {
+	seen := map[*SchemaStruct]bool{}
+	for _, parent := range extends {
+		structType, ok := c.assertStructType(pkg, parent)
+		if !ok || parent.Generic != nil {
+			c.errorf(parent.Pos, "can't extend %s (must be a struct type)", parent)
+			continue
+		}
+		if seen[structType] {
+			c.errorf(parent.Pos, "%s is extended more than once", parent)
+			continue
+		}
+		seen[structType] = true
+		out = append(out, structType)
+	}
+	return
+}
+
+// inheritFields prepends the fields of the structs that every model and struct extends to its own
+// fields, once all of them are compiled.
+func (c *configSchemaCompiler) inheritFields(schema *Schema) {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := map[*SchemaStruct]int{}
+
+	var resolve func(name string, extends []*SchemaStruct, fields []*SchemaField, pos Position) []*SchemaField
+	var resolveStruct func(structType *SchemaStruct) []*SchemaField
+	resolveStruct = func(structType *SchemaStruct) []*SchemaField {
+		switch state[structType] {
+		case visiting:
+			c.errorAt(structType.Pos, "struct %s extends itself", structType.Name)
+			return nil
+		case done:
+			return structType.Fields
+		}
+		state[structType] = visiting
+		structType.Fields = resolve(structType.Name, structType.Extends, structType.Fields, structType.Pos)
+		state[structType] = done
+		return structType.Fields
+	}
+	resolve = func(name string, extends []*SchemaStruct, fields []*SchemaField, pos Position) (out []*SchemaField) {
+		if len(extends) == 0 {
+			return fields
+		}
+		byName := map[string]*SchemaField{}
+		byWireName := map[string]*SchemaField{}
+		add := func(field *SchemaField) {
+			other, ok := byName[field.Name]
+			if !ok {
+				other, ok = byWireName[field.WireName]
+			}
+			if ok && other.Origin != field.Origin {
+				errorPos := field.Pos
+				if field.Origin != nil {
+					errorPos = pos
+				}
+				c.errorAt(errorPos, "%s conflicts with %s in %s", describeField(field), describeField(other), name)
+				return
+			}
+			byName[field.Name] = field
+			byWireName[field.WireName] = field
+			out = append(out, field)
+		}
+
+		for _, parent := range extends {
+			structType := schema.Struct(parent.Package, parent.Name)
+			if structType == nil {
+				continue
+			}
+			for _, field := range resolveStruct(structType) {
+				inherited := *field
+				inherited.Origin = parent
+				add(&inherited)
+			}
+		}
+		for _, field := range fields {
+			add(field)
+		}
+		return
+	}
+
+	for _, structType := range schema.Structs {
+		resolveStruct(structType)
+	}
+	for _, model := range schema.Models {
+		model.Fields = resolve(model.Name, model.Extends, model.Fields, model.Pos)
+	}
+}
+
+func describeField(field *SchemaField) string {
+	if field.Origin != nil {
+		return fmt.Sprintf("field %s inherited from %s", field.Name, field.Origin.Name)
+	}
+	return fmt.Sprintf("field %s", field.Name)
+}
+
+func (c *configSchemaCompiler) compileEnums() (out []*SchemaEnum) {
+	for _, v := range c.elements() {
+		if v.Enum == nil || v.Enum.Identifier.IsReserved() {
+			continue
+		}
+		if v.Enum.Backing != "" && v.Enum.Backing != "integer" && v.Enum.Backing != "string" {
+			c.errorf(v.Enum.Pos, "invalid type %s for enum %s (must be string or integer)", v.Enum.Backing, v.Enum.Identifier)
+			continue
+		}
+		backing := enumBacking(v.Enum)
+		enum := &SchemaEnum{
+			Name:     strcase.ToCamel(string(v.Enum.Identifier)),
+			Package:  c.packages[v],
+			Comment:  joinComments(v.Comments),
+			Backing:  backing,
+			Values:   c.enumValuesToConfig(v.Enum, backing),
+			Reserved: c.compileReservedEnumValues(v.Enum, backing),
+			Pos:      positionOf(v.Enum.Pos),
+		}
+		c.checkReservedEnumValues(enum)
+		out = append(out, enum)
+	}
+	return
+}
+
 func (c *configSchemaCompiler) compileLanguageOptions() (out SchemaOptions) {
 	out = SchemaOptions{}
-	for _, v := range c.ast.Types {
+	for _, v := range c.elements() {
 		opt := v.Option
 		if opt == nil {
 			continue
 		}
+		if opt.Key.IsReservedOptionKey() {
+			c.errorf(opt.Pos, "can't use option key %s, %s is a reserved word.", opt.Key, opt.Key)
+			continue
+		}
+		if opt.Language == "firestore" && opt.Key == "naming" && !FieldNaming(opt.Value).IsValid() {
+			c.errorf(opt.Pos, "invalid naming option %q (must be lower_camel, snake or verbatim)", opt.Value)
+			continue
+		}
 		if out[opt.Language] == nil {
 			out[opt.Language] = map[string]string{}
 		}
-		if opt.Key.IsReserved() {
-			err := errors.Errorf("firemodel/schema: can't use option key %s, %s is a reserved word.", opt.Key, opt.Key)
-			panic(err)
-		}
 		out[opt.Language][string(opt.Key)] = opt.Value
 	}
 	return
 }
 
-func (c *configSchemaCompiler) enumValuesToConfig(values []*ast.ASTEnumValue) (out []*SchemaEnumValue) {
-	for _, enumValue := range values {
-		out = append(out, &SchemaEnumValue{
-			Name:    strcase.ToSnake(enumValue.Name),
-			Comment: enumValue.Comment,
-		})
+// fieldNaming returns the naming policy of the schema, set with the firestore.naming option.
+func (c *configSchemaCompiler) fieldNaming() FieldNaming {
+	if naming, ok := c.options.Get("firestore")["naming"]; ok {
+		return FieldNaming(naming)
+	}
+	return FieldNamingLowerCamel
+}
+
+// modelNaming returns the naming policy of a model or union with options, which may override the
+// naming policy of the schema.
+func (c *configSchemaCompiler) modelNaming(options SchemaModelOptions) FieldNaming {
+	if naming, ok := options.Get("firestore")["naming"]; ok {
+		return FieldNaming(naming)
+	}
+	return c.fieldNaming()
+}
+
+// compileReservedFields returns the field names reserved by the reserved statements of a model or
+// struct.
+func (c *configSchemaCompiler) compileReservedFields(reserved []*ast.ASTReserved) (out []string) {
+	for _, statement := range reserved {
+		for _, value := range statement.Values {
+			if value.Str == nil {
+				c.errorf(value.Pos, "invalid reserved field name %s (must be a string)", value)
+				continue
+			}
+			out = append(out, *value.Str)
+		}
 	}
 	return
 }
 
-func (c *configSchemaCompiler) compileModelFields(elements []*ast.ASTModelElement) (out []*SchemaField) {
+// compileReservedEnumValues returns the names and stored values reserved by the reserved
+// statements of enum. Strings are names, or stored values of string enums; integers are stored
+// values of integer enums.
+func (c *configSchemaCompiler) compileReservedEnumValues(enum *ast.ASTEnum, backing EnumBacking) (out []interface{}) {
+	for _, statement := range enum.Reserved {
+		for _, value := range statement.Values {
+			switch {
+			case value.Str != nil:
+				out = append(out, *value.Str)
+			case value.Number != nil && backing == EnumBackingInteger:
+				if literal := c.enumLiteral(value.Pos, *value.Number, backing); literal != nil {
+					out = append(out, literal)
+				}
+			default:
+				c.errorf(value.Pos, "invalid reserved value %s for enum %s (must be a name or a stored value)", value, enum.Identifier)
+			}
+		}
+	}
+	return
+}
+
+// checkReservedEnumValues reports values of enum that reuse a reserved name or stored value.
+func (c *configSchemaCompiler) checkReservedEnumValues(enum *SchemaEnum) {
+	reserved := map[interface{}]bool{}
+	for _, value := range enum.Reserved {
+		reserved[value] = true
+		if name, ok := value.(string); ok && !enum.IsIntegerBacked() {
+			reserved[strcase.ToScreamingSnake(name)] = true
+		}
+	}
+	for _, value := range enum.Values {
+		if reserved[value.Name] {
+			c.errorAt(value.Pos, "enum value %s is reserved in %s", value.Name, enum.Name)
+			continue
+		}
+		for _, wireValue := range append([]interface{}{value.WireValue}, value.Aliases...) {
+			if wireValue != nil && reserved[wireValue] {
+				c.errorAt(value.Pos, "enum value %s is stored as %#v, which is reserved in %s", value.Name, wireValue, enum.Name)
+			}
+		}
+	}
+}
+
+// checkReservedFields reports fields of the models and structs of schema, including inherited
+// fields, that reuse a reserved name or are stored under the key of one.
+func (c *configSchemaCompiler) checkReservedFields(schema *Schema) {
+	check := func(name string, reserved []string, naming FieldNaming, fields []*SchemaField, pos Position) {
+		if len(reserved) == 0 {
+			return
+		}
+		names := map[string]bool{}
+		wireNames := map[string]bool{}
+		for _, name := range reserved {
+			names[strcase.ToSnake(name)] = true
+			wireNames[name] = true
+			wireNames[naming.Apply(name)] = true
+		}
+		for _, field := range fields {
+			errorPos := field.Pos
+			if field.Origin != nil {
+				errorPos = pos
+			}
+			switch {
+			case names[field.Name]:
+				c.errorAt(errorPos, "%s is reserved in %s", describeField(field), name)
+			case wireNames[field.WireName]:
+				c.errorAt(errorPos, "%s is stored as %s, which is reserved in %s", describeField(field), field.WireName, name)
+			}
+		}
+	}
+	for _, model := range schema.Models {
+		check(model.Name, model.Reserved, c.modelNaming(model.Options), model.Fields, model.Pos)
+	}
+	for _, structType := range schema.Structs {
+		check(structType.Name, structType.Reserved, c.fieldNaming(), structType.Fields, structType.Pos)
+	}
+}
+
+func enumBacking(enum *ast.ASTEnum) EnumBacking {
+	if enum.Backing == "integer" {
+		return EnumBackingInteger
+	}
+	return EnumBackingString
+}
+
+func (c *configSchemaCompiler) enumValuesToConfig(enum *ast.ASTEnum, backing EnumBacking) (out []*SchemaEnumValue) {
+	stored := map[interface{}]string{}
+	names := map[string]*ast.ASTEnumValue{}
+	for _, enumValue := range enum.Values {
+		name := strcase.ToSnake(enumValue.Name)
+		if other, ok := names[name]; ok {
+			c.errorf(enumValue.Pos, "enum values %s and %s are both named %s (declared at %s)", other.Name, enumValue.Name, name, positionOf(other.Pos))
+			continue
+		}
+		names[name] = enumValue
+
+		value := &SchemaEnumValue{
+			Name:      strcase.ToSnake(enumValue.Name),
+			WireValue: c.enumWireValue(enum, enumValue, backing),
+			Comment:   joinComments(enumValue.Comments),
+			Pos:       positionOf(enumValue.Pos),
+		}
+		for _, option := range enumValue.Options {
+			switch option.Key {
+			case "alias":
+				if alias := c.enumLiteral(option.Pos, option.Value, backing); alias != nil {
+					value.Aliases = append(value.Aliases, alias)
+				}
+			case "deprecated":
+				value.Deprecated = option.Value == "true"
+			default:
+				c.errorf(option.Pos, "unknown enum value option %s (must be alias or deprecated)", option.Key)
+			}
+		}
+
+		for _, wireValue := range append([]interface{}{value.WireValue}, value.Aliases...) {
+			if wireValue == nil {
+				continue
+			}
+			if other, ok := stored[wireValue]; ok && other == value.Name {
+				c.errorf(enumValue.Pos, "enum value %s is stored as %#v more than once", value.Name, wireValue)
+				continue
+			} else if ok {
+				c.errorf(enumValue.Pos, "enum values %s and %s are both stored as %#v", other, value.Name, wireValue)
+				continue
+			}
+			stored[wireValue] = value.Name
+		}
+		out = append(out, value)
+	}
+	return
+}
+
+// enumWireValue returns the value that enumValue is stored as. Values of string enums are stored as
+// their SCREAMING_SNAKE_CASE name unless set explicitly; values of integer enums must be set.
+func (c *configSchemaCompiler) enumWireValue(enum *ast.ASTEnum, enumValue *ast.ASTEnumValue, backing EnumBacking) interface{} {
+	value := enumValue.Value
+	if value == nil {
+		if backing == EnumBackingInteger {
+			c.errorf(enumValue.Pos, "value %s of integer enum %s must be set explicitly (e.g. %s = 1)", enumValue.Name, enum.Identifier, enumValue.Name)
+			return nil
+		}
+		return strcase.ToScreamingSnake(enumValue.Name)
+	}
+	switch {
+	case backing == EnumBackingInteger && value.Number != nil:
+		return c.enumLiteral(value.Pos, *value.Number, backing)
+	case backing == EnumBackingString && value.Str != nil:
+		return c.enumLiteral(value.Pos, *value.Str, backing)
+	}
+	c.errorf(value.Pos, "invalid value %s for %s (enum %s is stored as %s)", value, enumValue.Name, enum.Identifier, backing)
+	return nil
+}
+
+// enumLiteral converts a literal stored value of an enum to a string or int64, depending on the
+// backing of the enum.
+func (c *configSchemaCompiler) enumLiteral(pos lexer.Position, literal string, backing EnumBacking) interface{} {
+	if backing == EnumBackingString {
+		if literal == "" {
+			c.errorf(pos, "enum values can't be stored as the empty string")
+			return nil
+		}
+		return literal
+	}
+	i, err := strconv.ParseInt(literal, 10, 64)
+	if err != nil {
+		c.errorf(pos, "invalid value %s for integer enum", literal)
+		return nil
+	}
+	return i
+}
+
+func (c *configSchemaCompiler) compileModelFields(pkg string, naming FieldNaming, elements []*ast.ASTModelElement) (out []*SchemaField) {
+	names := map[string]*ast.ASTField{}
 	for _, element := range elements {
 		field := element.Field
-		if field == nil {
+		if field == nil || !c.checkFieldName(names, field) {
 			continue
 		}
 		if field.Type.Base.IsCollection() {
 			continue // handled in compileCollections
 		}
 
+		fieldType := c.compileFieldType(pkg, field.Type)
+		if fieldType == nil {
+			continue
+		}
+		alias := c.assertAliasType(pkg, field.Type)
+		options := c.compileFieldOptions(field.Options)
 		out = append(out, &SchemaField{
-			Name:    strcase.ToSnake(field.Name),
-			Comment: field.Comment,
-			Type:    c.compileFieldType(field.Type),
+			Name:        strcase.ToSnake(field.Name),
+			WireName:    c.wireName(field, naming, options),
+			Comment:     joinComments(field.Comments),
+			Type:        fieldType,
+			Presence:    fieldPresence(field),
+			Nullable:    field.Nullable,
+			Default:     c.compileDefault(field, fieldType),
+			Constraints: append(c.aliasConstraints(alias), c.compileConstraints("field "+field.Name, field, fieldType)...),
+			Options:     options,
+			Deprecated:  options.Get("")["deprecated"] == "true",
+			Alias:       alias,
+			Pos:         positionOf(field.Pos),
 		})
 	}
+	c.checkWireNames(out)
 	return
 }
 
-func (c *configSchemaCompiler) compileStructFields(elements []*ast.ASTStructElement) (out []*SchemaField) {
+func (c *configSchemaCompiler) compileStructFields(pkg string, naming FieldNaming, elements []*ast.ASTStructElement) (out []*SchemaField) {
+	names := map[string]*ast.ASTField{}
 	for _, element := range elements {
 		field := element.Field
-		if field == nil {
+		if field == nil || !c.checkFieldName(names, field) {
 			continue
 		}
 		if field.Type.Base.IsCollection() {
-			continue // handled in compileCollections
+			c.errorf(field.Type.Pos, "can't use collections in structs (got %s); collections may only be nested in models", field.Type)
+			continue
 		}
 
+		fieldType := c.compileFieldType(pkg, field.Type)
+		if fieldType == nil {
+			continue
+		}
+		alias := c.assertAliasType(pkg, field.Type)
+		options := c.compileFieldOptions(field.Options)
 		out = append(out, &SchemaField{
-			Name:    strcase.ToSnake(field.Name),
-			Comment: field.Comment,
-			Type:    c.compileFieldType(field.Type),
+			Name:        strcase.ToSnake(field.Name),
+			WireName:    c.wireName(field, naming, options),
+			Comment:     joinComments(field.Comments),
+			Type:        fieldType,
+			Presence:    fieldPresence(field),
+			Nullable:    field.Nullable,
+			Default:     c.compileDefault(field, fieldType),
+			Constraints: append(c.aliasConstraints(alias), c.compileConstraints("field "+field.Name, field, fieldType)...),
+			Options:     options,
+			Deprecated:  options.Get("")["deprecated"] == "true",
+			Alias:       alias,
+			Pos:         positionOf(field.Pos),
 		})
 	}
+	c.checkWireNames(out)
 	return
 }
 
-func (c *configSchemaCompiler) compileCollections(elements []*ast.ASTModelElement) (out []*SchemaNestedCollection) {
+func (c *configSchemaCompiler) compileCollections(pkg string, elements []*ast.ASTModelElement) (out []*SchemaNestedCollection) {
 	for _, element := range elements {
 		field := element.Field
 		if field == nil {
@@ -237,34 +907,72 @@ func (c *configSchemaCompiler) compileCollections(elements []*ast.ASTModelElemen
 		if !field.Type.Base.IsCollection() {
 			continue // handled in compileFields
 		}
-		modelType, ok := c.assertModelType(field.Type.Generic)
+		if len(field.Options) > 0 {
+			c.errorf(field.Options[0].Pos, "can't use options on collection %s", field.Name)
+		}
+		if field.Modifier != "" || field.Nullable {
+			c.errorf(field.Pos, "can't use presence modifiers on collection %s", field.Name)
+		}
+		modelType, ok := c.assertModelType(pkg, field.Type.Generic)
 		if !ok {
-			err := errors.Errorf("invalid collection type: %s", field.Type)
-			panic(err)
+			c.errorf(field.Type.Pos, "invalid collection type: %s (must be collection<T> where T is a model type)", field.Type)
+			continue
 		}
 		out = append(out, &SchemaNestedCollection{
 			Name:    field.Name,
-			Comment: field.Comment,
+			Comment: joinComments(field.Comments),
 			Type:    modelType,
+			Pos:     positionOf(field.Pos),
 		})
 	}
 	return
 }
 
-func (c *configSchemaCompiler) compileFieldType(astFieldType *ast.ASTFieldType) SchemaFieldType {
+// compileFieldType returns the SchemaFieldType for astFieldType, as referenced from package pkg. If
+// the type is invalid, the problem is recorded as a diagnostic and nil is returned.
+func (c *configSchemaCompiler) compileFieldType(pkg string, astFieldType *ast.ASTFieldType) SchemaFieldType {
 	if c.enums == nil {
 		panic("bug: enum types not yet registered")
 	}
-	if enum, ok := c.assertEnumType(astFieldType); ok {
+	if qualifier, _ := astFieldType.Base.Qualified(); qualifier != "" && !c.isPackage(qualifier) {
+		c.errorf(astFieldType.Pos, "unknown package %s in %s", qualifier, astFieldType)
+		return nil
+	}
+	if enum, ok := c.assertEnumType(pkg, astFieldType); ok {
+		if astFieldType.Generic != nil {
+			c.errorf(astFieldType.Pos, "generic enums are not supported: %s", astFieldType)
+			return nil
+		}
 		return &Enum{T: enum}
 	}
-	if _, ok := c.assertModelType(astFieldType); ok {
-		err := errors.Errorf("firemodel/schema: can't use models as field types (got %s); please use reference, collection or switch model to struct instead", astFieldType)
-		panic(err)
+	if _, ok := c.assertModelType(pkg, astFieldType); ok {
+		c.errorf(astFieldType.Pos, "can't use models as field types (got %s); please use reference, collection or switch model to struct instead", astFieldType)
+		return nil
 	}
-	if structT, ok := c.assertStructType(astFieldType); ok {
+	if structT, ok := c.assertStructType(pkg, astFieldType); ok {
 		return &Struct{T: structT}
 	}
+	if union, ok := c.assertUnionType(pkg, astFieldType); ok {
+		if astFieldType.Generic != nil {
+			c.errorf(astFieldType.Pos, "generic unions are not supported: %s", astFieldType)
+			return nil
+		}
+		return &Union{T: union}
+	}
+	if alias := c.assertAliasType(pkg, astFieldType); alias != nil {
+		if astFieldType.Generic != nil {
+			c.errorf(astFieldType.Pos, "generic types are not supported: %s", astFieldType)
+			return nil
+		}
+		if compiled := c.compileAlias(alias); compiled != nil {
+			return compiled.Type
+		}
+		return nil
+	}
+	if astFieldType.Value != nil && astFieldType.Base != ast.Map {
+		c.errorf(astFieldType.Value.Pos, "too many type parameters in %s (only maps have a key type)", astFieldType)
+		return nil
+	}
 	switch astFieldType.Base {
 	case ast.Boolean:
 		return &Boolean{}
@@ -285,74 +993,159 @@ func (c *configSchemaCompiler) compileFieldType(astFieldType *ast.ASTFieldType)
 	case ast.URL:
 		return &URL{}
 	case ast.Map:
+		if astFieldType.Value != nil {
+			keyType := c.compileMapKeyType(pkg, astFieldType)
+			valueType := c.compileFieldType(pkg, astFieldType.Value)
+			if keyType == nil || valueType == nil {
+				return nil
+			}
+			if _, ok := keyType.(*String); ok {
+				return &Map{T: valueType}
+			}
+			return &Map{K: keyType, T: valueType}
+		}
 		if generic := astFieldType.Generic; generic != nil {
-			return &Map{T: c.compileFieldType(generic)}
+			valueType := c.compileFieldType(pkg, generic)
+			if valueType == nil {
+				return nil
+			}
+			return &Map{T: valueType}
 		}
 		return &Map{}
 	case ast.Array:
 		if generic := astFieldType.Generic; generic != nil {
-			return &Array{T: c.compileFieldType(generic)}
+			elemType := c.compileFieldType(pkg, generic)
+			if elemType == nil {
+				return nil
+			}
+			return &Array{T: elemType}
 		}
 		return &Array{}
 	case ast.Reference:
 		if astFieldType.Generic == nil {
 			return &Reference{}
-		} else if modelType, ok := c.assertModelType(astFieldType.Generic); ok {
+		} else if modelType, ok := c.assertModelType(pkg, astFieldType.Generic); ok {
 			return &Reference{T: modelType}
 		} else {
-			err := errors.Errorf("firemodel: invalid generic type %s in %s<%s> (must be a model type)", astFieldType.Generic, astFieldType.Base, astFieldType.Generic)
-			panic(err)
+			c.errorf(astFieldType.Generic.Pos, "invalid generic type %s in %s (must be a model type)", astFieldType.Generic, astFieldType)
+			return nil
+		}
+	}
+
+	c.errorf(astFieldType.Pos, "invalid type: %s", astFieldType.Base)
+	return nil
+}
+
+// compileMapKeyType returns the key type of astFieldType, which is declared as map<K, V>. Firestore
+// keys are strings, so the key type must be string or an enum stored as strings.
+func (c *configSchemaCompiler) compileMapKeyType(pkg string, astFieldType *ast.ASTFieldType) SchemaFieldType {
+	keyType := c.compileFieldType(pkg, astFieldType.Generic)
+	switch keyType := keyType.(type) {
+	case nil:
+		return nil
+	case *String:
+		return keyType
+	case *Enum:
+		if !keyType.T.IsIntegerBacked() {
+			return keyType
 		}
 	}
+	c.errorf(astFieldType.Generic.Pos, "invalid key type %s in %s (must be string or an enum stored as strings)", astFieldType.Generic, astFieldType)
+	return nil
+}
 
-	err := errors.Errorf("invalid type: %s", astFieldType.Base)
-	panic(err)
+// lookupPackages returns the packages to search, in order, for a type referenced from package pkg.
+// Qualified references only search the named package; unqualified references search pkg and then
+// the default package.
+func lookupPackages(pkg string, astFieldType *ast.ASTFieldType) (packages []string, name string) {
+	qualifier, name := astFieldType.Base.Qualified()
+	if qualifier != "" {
+		return []string{qualifier}, name
+	}
+	if pkg == "" {
+		return []string{""}, name
+	}
+	return []string{pkg, ""}, name
 }
 
-func (c *configSchemaCompiler) assertModelType(astFieldType *ast.ASTFieldType) (*SchemaModel, bool) {
+func (c *configSchemaCompiler) assertModelType(pkg string, astFieldType *ast.ASTFieldType) (*SchemaModel, bool) {
 	if c.models == nil {
 		panic("bug: model types not yet registered")
 	}
 	if astFieldType == nil {
 		return nil, false
 	}
-	astType := astFieldType.Base
-	for _, model := range c.models {
-		if model.Name == strcase.ToCamel(string(astType)) {
-			return model, true
+	packages, name := lookupPackages(pkg, astFieldType)
+	for _, lookupPkg := range packages {
+		for _, model := range c.models {
+			if model.Package == lookupPkg && model.Name == strcase.ToCamel(name) {
+				return model, true
+			}
 		}
 	}
 	return nil, false
 }
 
-func (c *configSchemaCompiler) assertStructType(astFieldType *ast.ASTFieldType) (*SchemaStruct, bool) {
+func (c *configSchemaCompiler) assertStructType(pkg string, astFieldType *ast.ASTFieldType) (*SchemaStruct, bool) {
 	if c.structs == nil {
 		panic("bug: model types not yet registered")
 	}
 	if astFieldType == nil {
 		return nil, false
 	}
-	astType := astFieldType.Base
-	for _, schemaStruct := range c.structs {
-		if schemaStruct.Name == strcase.ToCamel(string(astType)) {
-			return schemaStruct, true
+	packages, name := lookupPackages(pkg, astFieldType)
+	for _, lookupPkg := range packages {
+		for _, schemaStruct := range c.structs {
+			if schemaStruct.Package == lookupPkg && schemaStruct.Name == strcase.ToCamel(name) {
+				return schemaStruct, true
+			}
 		}
 	}
 	return nil, false
 }
 
-func (c *configSchemaCompiler) assertEnumType(astType *ast.ASTFieldType) (*SchemaEnum, bool) {
-	if astType == nil {
+func (c *configSchemaCompiler) assertUnionType(pkg string, astFieldType *ast.ASTFieldType) (*SchemaUnion, bool) {
+	if astFieldType == nil {
 		return nil, false
 	}
-	for _, enum := range c.enums {
-		if enum.Name == strcase.ToCamel(string(astType.Base)) {
-			if astType.Generic != nil {
-				panic(errors.Errorf("generic enums are not supported: %s %v", astType.Base, astType.Generic))
+	packages, name := lookupPackages(pkg, astFieldType)
+	for _, lookupPkg := range packages {
+		for _, union := range c.unions {
+			if union.Package == lookupPkg && union.Name == strcase.ToCamel(name) {
+				return union, true
 			}
-			return enum, true
 		}
+	}
+	return nil, false
+}
+
+// assertAliasType returns the alias that astFieldType refers to, if any.
+func (c *configSchemaCompiler) assertAliasType(pkg string, astFieldType *ast.ASTFieldType) *SchemaAlias {
+	if astFieldType == nil {
+		return nil
+	}
+	packages, name := lookupPackages(pkg, astFieldType)
+	for _, lookupPkg := range packages {
+		for _, alias := range c.aliases {
+			if alias.Package == lookupPkg && alias.Name == strcase.ToCamel(name) {
+				return alias
+			}
+		}
+	}
+	return nil
+}
 
+func (c *configSchemaCompiler) assertEnumType(pkg string, astType *ast.ASTFieldType) (*SchemaEnum, bool) {
+	if astType == nil {
+		return nil, false
+	}
+	packages, name := lookupPackages(pkg, astType)
+	for _, lookupPkg := range packages {
+		for _, enum := range c.enums {
+			if enum.Package == lookupPkg && enum.Name == strcase.ToCamel(name) {
+				return enum, true
+			}
+		}
 	}
 	return nil, false
 }
@@ -364,14 +1157,234 @@ func (c *configSchemaCompiler) compileModelOptions(elements []*ast.ASTModelEleme
 		if option == nil {
 			continue
 		}
+		if option.Key.IsReservedOptionKey() {
+			c.errorf(option.Pos, "can't use option key %s, %s is a reserved word.", option.Key, option.Key)
+			continue
+		}
 		if out[option.Language] == nil {
 			out[option.Language] = map[string]string{}
 		}
-		if option.Key.IsReserved() {
-			err := errors.Errorf("firemodel/schema: can't use option key %s, %s is a reserved word.", option.Key, option.Key)
-			panic(err)
-		}
 		out[option.Language][string(option.Key)] = option.Value
+
+		if option.Language == "firestore" {
+			c.checkFirestoreModelOption(option, out)
+		}
 	}
 	return out
 }
+
+// compileDefault returns the default value of field, checked against its type.
+func (c *configSchemaCompiler) compileDefault(field *ast.ASTField, fieldType SchemaFieldType) interface{} {
+	value := field.Default
+	if value == nil {
+		return nil
+	}
+	if field.Modifier == "optional" || field.Nullable {
+		c.errorf(value.Pos, "can't set a default value on optional or nullable field %s", field.Name)
+		return nil
+	}
+
+	switch fieldType := fieldType.(type) {
+	case *Boolean:
+		if value.Bool != nil {
+			return *value.Bool == "true"
+		}
+	case *Integer:
+		if value.Number != nil {
+			if i, err := strconv.ParseInt(*value.Number, 10, 64); err == nil {
+				return i
+			}
+		}
+	case *Double:
+		if value.Number != nil {
+			if f, err := strconv.ParseFloat(*value.Number, 64); err == nil {
+				return f
+			}
+		}
+	case *String, *URL:
+		if value.Str != nil {
+			return *value.Str
+		}
+	case *Enum:
+		if value.Ident != nil {
+			name := strcase.ToSnake(*value.Ident)
+			for _, enumValue := range c.enumDecls[fieldType.T].Values {
+				if strcase.ToSnake(enumValue.Name) == name {
+					return &SchemaEnumValue{Name: name}
+				}
+			}
+			c.errorf(value.Pos, "invalid default value %s for field %s (%s has no value %s)", value, field.Name, fieldType.T.Name, *value.Ident)
+			return nil
+		}
+	default:
+		c.errorf(value.Pos, "can't set a default value on %s field %s", field.Type, field.Name)
+		return nil
+	}
+	c.errorf(value.Pos, "invalid default value %s for %s field %s", value, field.Type, field.Name)
+	return nil
+}
+
+// compileConstraints returns the validation constraints set with the options of field, checked
+// against its type. Errors refer to the field as subject, e.g. "field name".
+func (c *configSchemaCompiler) compileConstraints(subject string, field *ast.ASTField, fieldType SchemaFieldType) (out []*SchemaConstraint) {
+	limits := map[ConstraintKind]float64{}
+	for _, option := range field.Options {
+		kind := ConstraintKind(option.Key)
+		var ok bool
+		switch kind {
+		case ConstraintMin, ConstraintMax:
+			switch fieldType.(type) {
+			case *Integer, *Double:
+				ok = true
+			}
+		case ConstraintMinLength, ConstraintMaxLength, ConstraintPattern:
+			_, ok = fieldType.(*String)
+		case ConstraintNonEmpty:
+			switch fieldType.(type) {
+			case *String, *Array, *Map:
+				ok = true
+			}
+		case ConstraintMinItems, ConstraintMaxItems:
+			_, ok = fieldType.(*Array)
+		default:
+			continue // not a constraint
+		}
+		if !ok {
+			c.errorf(option.Pos, "can't use %s on %s %s", kind, field.Type, subject)
+			continue
+		}
+
+		constraint := &SchemaConstraint{Kind: kind}
+		switch kind {
+		case ConstraintPattern:
+			if _, err := regexp.Compile(option.Value); err != nil {
+				c.errorf(option.Pos, "invalid pattern for %s: %s", subject, err)
+				continue
+			}
+			constraint.Pattern = option.Value
+		case ConstraintNonEmpty:
+			if option.Value != "true" {
+				continue
+			}
+		default:
+			limit, err := strconv.ParseFloat(option.Value, 64)
+			if err != nil {
+				c.errorf(option.Pos, "invalid %s for %s: %s is not a number", kind, subject, option.Value)
+				continue
+			}
+			_, isDouble := fieldType.(*Double)
+			if !isDouble && limit != float64(int64(limit)) {
+				c.errorf(option.Pos, "invalid %s for %s: %s is not an integer", kind, subject, option.Value)
+				continue
+			}
+			if kind != ConstraintMin && kind != ConstraintMax && limit < 0 {
+				c.errorf(option.Pos, "invalid %s for %s: %s is negative", kind, subject, option.Value)
+				continue
+			}
+			constraint.Limit = limit
+			limits[kind] = limit
+		}
+		out = append(out, constraint)
+	}
+
+	for _, bounds := range [][2]ConstraintKind{
+		{ConstraintMin, ConstraintMax},
+		{ConstraintMinLength, ConstraintMaxLength},
+		{ConstraintMinItems, ConstraintMaxItems},
+	} {
+		min, max := bounds[0], bounds[1]
+		minLimit, hasMin := limits[min]
+		maxLimit, hasMax := limits[max]
+		if hasMin && hasMax && minLimit > maxLimit {
+			c.errorf(field.Pos, "%s of %s is greater than its %s", min, subject, max)
+		}
+	}
+	return
+}
+
+func fieldPresence(field *ast.ASTField) FieldPresence {
+	switch field.Modifier {
+	case "required":
+		return PresenceRequired
+	case "optional":
+		return PresenceOptional
+	default:
+		return PresenceDefault
+	}
+}
+
+var wireNamePattern = regexp.MustCompile("^[a-zA-Z_][a-zA-Z0-9_]*$")
+
+// wireName returns the key that field is stored under in Firestore: the firestore.name option of
+// the field if it is set, or the name of the field according to the naming policy.
+func (c *configSchemaCompiler) wireName(field *ast.ASTField, naming FieldNaming, options SchemaFieldOptions) string {
+	if name, ok := options.Get("firestore")["name"]; ok {
+		if !wireNamePattern.MatchString(name) {
+			for _, option := range field.Options {
+				if option.Key == "firestore.name" {
+					c.errorf(option.Pos, "invalid firestore.name %q (must be a letter or underscore followed by letters, digits or underscores)", name)
+				}
+			}
+		}
+		return name
+	}
+	return naming.Apply(field.Name)
+}
+
+// checkWireNames reports fields of a single type that would be stored under the same key.
+func (c *configSchemaCompiler) checkWireNames(fields []*SchemaField) {
+	seen := map[string]*SchemaField{}
+	for _, field := range fields {
+		if other, ok := seen[field.WireName]; ok {
+			c.errorAt(field.Pos, "fields %s and %s are both stored as %s (declared at %s)", other.Name, field.Name, field.WireName, other.Pos)
+			continue
+		}
+		seen[field.WireName] = field
+	}
+}
+
+// compileFieldOptions returns the options set on a field, or nil if there are none.
+func (c *configSchemaCompiler) compileFieldOptions(options []*ast.ASTFieldOption) (out SchemaFieldOptions) {
+	for _, option := range options {
+		namespace, key := option.Namespaced()
+		if key.IsReservedOptionKey() {
+			c.errorf(option.Pos, "can't use option key %s, %s is a reserved word.", key, key)
+			continue
+		}
+		if _, ok := out[namespace][string(key)]; ok {
+			c.errorf(option.Pos, "option %s is set more than once", option.Key)
+			continue
+		}
+		if out == nil {
+			out = SchemaFieldOptions{}
+		}
+		if out[namespace] == nil {
+			out[namespace] = map[string]string{}
+		}
+		out[namespace][string(key)] = option.Value
+	}
+	return out
+}
+
+// checkFirestoreModelOption validates the value of a firestore.* model option as soon as it is
+// declared, so that a bad value is reported at its position rather than by a modeler.
+func (c *configSchemaCompiler) checkFirestoreModelOption(option *ast.ASTOption, options SchemaModelOptions) {
+	var err error
+	switch option.Key {
+	case "path":
+		_, _, err = options.GetFirestorePath()
+	case "model_name":
+		_, err = options.GetFirestoreModelName()
+	case "naming":
+		if !FieldNaming(option.Value).IsValid() {
+			err = errors.Errorf("invalid naming option %q (must be lower_camel, snake or verbatim)", option.Value)
+		}
+	case "discriminator":
+		if !wireNamePattern.MatchString(option.Value) {
+			err = errors.Errorf("invalid discriminator %q (must be a letter or underscore followed by letters, digits or underscores)", option.Value)
+		}
+	}
+	if err != nil {
+		c.errorf(option.Pos, "%s", strings.TrimPrefix(err.Error(), "firemodel: "))
+	}
+}
@@ -1,8 +1,10 @@
 package firemodel
 
 import (
+	"fmt"
 	"testing"
 
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"gotest.tools/assert"
 
 	"os"
@@ -41,8 +43,9 @@ func TestParseSchema(t *testing.T) {
 						Name: "SimpleModel",
 						Fields: []*SchemaField{
 							{
-								Name: "foo",
-								Type: &String{},
+								Name:     "foo",
+								WireName: "foo",
+								Type:     &String{},
 							},
 						},
 						Options: SchemaModelOptions{},
@@ -60,58 +63,70 @@ func TestParseSchema(t *testing.T) {
 						Comment: "A Test is a test model.",
 						Fields: []*SchemaField{
 							{
-								Name:    "name",
-								Comment: "The name.",
-								Type:    &String{},
+								Name:     "name",
+								WireName: "name",
+								Comment:  "The name.",
+								Type:     &String{},
 							},
 							{
-								Name:    "age",
-								Comment: "The age.",
-								Type:    &Integer{},
+								Name:     "age",
+								WireName: "age",
+								Comment:  "The age.",
+								Type:     &Integer{},
 							},
 							{
-								Name:    "pi",
-								Comment: "The number pi.",
-								Type:    &Double{},
+								Name:     "pi",
+								WireName: "pi",
+								Comment:  "The number pi.",
+								Type:     &Double{},
 							},
 							{
-								Name:    "birthdate",
-								Comment: "The birth date.",
-								Type:    &Timestamp{},
+								Name:     "birthdate",
+								WireName: "birthdate",
+								Comment:  "The birth date.",
+								Type:     &Timestamp{},
 							},
 							{
-								Name:    "is_good",
-								Comment: "True if it is good.",
-								Type:    &Boolean{},
+								Name:     "is_good",
+								WireName: "isGood",
+								Comment:  "True if it is good.",
+								Type:     &Boolean{},
 							},
 							{
-								Name: "data",
-								Type: &Bytes{},
+								Name:     "data",
+								WireName: "data",
+								Type:     &Bytes{},
 							},
 							{
-								Name: "friend",
-								Type: &Reference{},
+								Name:     "friend",
+								WireName: "friend",
+								Type:     &Reference{},
 							},
 							{
-								Name: "location",
-								Type: &GeoPoint{},
+								Name:     "location",
+								WireName: "location",
+								Type:     &GeoPoint{},
 							},
 							{
-								Name: "colors",
-								Type: &Array{},
+								Name:     "colors",
+								WireName: "colors",
+								Type:     &Array{},
 							},
 							{
-								Name: "meta",
-								Type: &Map{},
+								Name:     "meta",
+								WireName: "meta",
+								Type:     &Map{},
 							},
 							{
-								Name:    "a_file",
-								Comment: "Fake types...",
-								Type:    &File{},
+								Name:     "a_file",
+								WireName: "aFile",
+								Comment:  "Fake types...",
+								Type:     &File{},
 							},
 							{
-								Name: "an_url",
-								Type: &URL{},
+								Name:     "an_url",
+								WireName: "anUrl",
+								Type:     &URL{},
 							},
 						},
 						Options: SchemaModelOptions{},
@@ -131,52 +146,59 @@ func TestParseSchema(t *testing.T) {
 						Name: "TestModel",
 						Fields: []*SchemaField{
 							{
-								Name: "other",
-								Type: &Reference{T: &SchemaModel{Name: "TestModel"}},
+								Name:     "other",
+								WireName: "other",
+								Type:     &Reference{T: &SchemaModel{Name: "TestModel"}},
 							},
 							{
-								Name: "unspecified_other",
-								Type: &Reference{},
+								Name:     "unspecified_other",
+								WireName: "unspecifiedOther",
+								Type:     &Reference{},
 							},
 							{
-								Name: "primative_ary",
-								Type: &Array{T: &String{}},
+								Name:     "primative_ary",
+								WireName: "primativeAry",
+								Type:     &Array{T: &String{}},
 							},
 							{
-								Name: "struct_ary",
-								Type: &Array{T: &Struct{T: &SchemaStruct{Name: "TestStruct"}}},
+								Name:     "struct_ary",
+								WireName: "structAry",
+								Type:     &Array{T: &Struct{T: &SchemaStruct{Name: "TestStruct"}}},
 							},
 							{
-								Name: "enum_ary",
-								Type: &Array{T: &Enum{T: &SchemaEnum{Name: "TestEnum"}}},
+								Name:     "enum_ary",
+								WireName: "enumAry",
+								Type:     &Array{T: &Enum{T: &SchemaEnum{Name: "TestEnum"}}},
 							},
 							{
-								Name: "reference_ary",
-								Type: &Array{T: &Reference{T: &SchemaModel{Name: "TestModel"}}},
+								Name:     "reference_ary",
+								WireName: "referenceAry",
+								Type:     &Array{T: &Reference{T: &SchemaModel{Name: "TestModel"}}},
 							},
 							{
-								Name: "nested_ary",
-								Type: &Array{T: &Array{&String{}}},
+								Name:     "generic_ary",
+								WireName: "genericAry",
+								Type:     &Array{},
 							},
 							{
-								Name: "generic_ary",
-								Type: &Array{},
+								Name:     "primative_map",
+								WireName: "primativeMap",
+								Type:     &Map{T: &String{}},
 							},
 							{
-								Name: "primative_map",
-								Type: &Map{T: &String{}},
+								Name:     "struct_map",
+								WireName: "structMap",
+								Type:     &Map{T: &Struct{T: &SchemaStruct{Name: "TestStruct"}}},
 							},
 							{
-								Name: "struct_map",
-								Type: &Map{T: &Struct{T: &SchemaStruct{Name: "TestStruct"}}},
+								Name:     "enum_map",
+								WireName: "enumMap",
+								Type:     &Map{T: &Enum{T: &SchemaEnum{Name: "TestEnum"}}},
 							},
 							{
-								Name: "enum_map",
-								Type: &Map{T: &Enum{T: &SchemaEnum{Name: "TestEnum"}}},
-							},
-							{
-								Name: "generic_map",
-								Type: &Map{},
+								Name:     "generic_map",
+								WireName: "genericMap",
+								Type:     &Map{},
 							},
 						},
 						Options: SchemaModelOptions{},
@@ -198,8 +220,9 @@ func TestParseSchema(t *testing.T) {
 						Name: "TestModel",
 						Fields: []*SchemaField{
 							{
-								Name: "url",
-								Type: &URL{},
+								Name:     "url",
+								WireName: "url",
+								Type:     &URL{},
 							},
 						},
 						Options: SchemaModelOptions{},
@@ -217,17 +240,21 @@ func TestParseSchema(t *testing.T) {
 						Name:    "Direction",
 						Values: []*SchemaEnumValue{
 							{
-								Comment: "Leftwards.",
-								Name:    "left",
+								Comment:   "Leftwards.",
+								Name:      "left",
+								WireValue: "LEFT",
 							},
 							{
-								Name: "right",
+								Name:      "right",
+								WireValue: "RIGHT",
 							},
 							{
-								Name: "up",
+								Name:      "up",
+								WireValue: "UP",
 							},
 							{
-								Name: "down",
+								Name:      "down",
+								WireValue: "DOWN",
 							},
 						},
 					},
@@ -237,8 +264,9 @@ func TestParseSchema(t *testing.T) {
 						Name: "TestModel",
 						Fields: []*SchemaField{
 							{
-								Comment: "The direction.",
-								Name:    "dir",
+								Comment:  "The direction.",
+								Name:     "dir",
+								WireName: "dir",
 								Type: &Enum{
 									T: &SchemaEnum{Name: "Direction"},
 								},
@@ -262,8 +290,9 @@ func TestParseSchema(t *testing.T) {
 						Name: "Operator",
 						Fields: []*SchemaField{
 							{
-								Name: "operator_name",
-								Type: &String{},
+								Name:     "operator_name",
+								WireName: "operatorName",
+								Type:     &String{},
 							},
 						},
 						Options: SchemaModelOptions{},
@@ -272,8 +301,9 @@ func TestParseSchema(t *testing.T) {
 						Name: "Component",
 						Fields: []*SchemaField{
 							{
-								Name: "component_name",
-								Type: &String{},
+								Name:     "component_name",
+								WireName: "componentName",
+								Type:     &String{},
 							},
 						},
 						Options: SchemaModelOptions{},
@@ -282,8 +312,9 @@ func TestParseSchema(t *testing.T) {
 						Name: "Machine",
 						Fields: []*SchemaField{
 							{
-								Name: "owner",
-								Type: &Reference{T: &SchemaModel{Name: "Operator"}},
+								Name:     "owner",
+								WireName: "owner",
+								Type:     &Reference{T: &SchemaModel{Name: "Operator"}},
 							},
 						},
 						Options: SchemaModelOptions{},
@@ -339,8 +370,9 @@ func TestParseSchema(t *testing.T) {
 						Name: "NormalCase",
 						Fields: []*SchemaField{
 							{
-								Name: "foo_bar",
-								Type: &String{},
+								Name:     "foo_bar",
+								WireName: "fooBar",
+								Type:     &String{},
 							},
 						},
 						Options: SchemaModelOptions{},
@@ -349,8 +381,9 @@ func TestParseSchema(t *testing.T) {
 						Name: "CamelCase",
 						Fields: []*SchemaField{
 							{
-								Name: "foo_bar",
-								Type: &String{},
+								Name:     "foo_bar",
+								WireName: "fooBar",
+								Type:     &String{},
 							},
 						},
 						Options: SchemaModelOptions{},
@@ -359,8 +392,9 @@ func TestParseSchema(t *testing.T) {
 						Name: "TitleCase",
 						Fields: []*SchemaField{
 							{
-								Name: "foo_bar",
-								Type: &String{},
+								Name:     "foo_bar",
+								WireName: "fooBar",
+								Type:     &String{},
 							},
 						},
 						Options: SchemaModelOptions{},
@@ -369,8 +403,9 @@ func TestParseSchema(t *testing.T) {
 						Name: "SnakeCase",
 						Fields: []*SchemaField{
 							{
-								Name: "foo_bar",
-								Type: &String{},
+								Name:     "foo_bar",
+								WireName: "fooBar",
+								Type:     &String{},
 							},
 						},
 						Options: SchemaModelOptions{},
@@ -404,8 +439,9 @@ func TestParseSchema(t *testing.T) {
 						Comment: "A sample struct",
 						Fields: []*SchemaField{
 							{
-								Name: "display_name",
-								Type: &String{},
+								Name:     "display_name",
+								WireName: "displayName",
+								Type:     &String{},
 							},
 						},
 					},
@@ -422,16 +458,643 @@ func TestParseSchema(t *testing.T) {
 						Comment: "Regression test.",
 						Fields: []*SchemaField{
 							{
-								Name: "name",
-								Type: &String{},
+								Name:     "name",
+								WireName: "name",
+								Type:     &String{},
+							},
+						},
+						Options: SchemaModelOptions{},
+					},
+				},
+				Options: SchemaOptions{},
+			},
+		},
+		{
+			name: "imports",
+			want: &Schema{
+				Models: []*SchemaModel{
+					{
+						Name: "Invoice",
+						Fields: []*SchemaField{
+							{
+								Name:     "total",
+								WireName: "total",
+								Type:     &Struct{T: &SchemaStruct{Name: "Money"}},
+							},
+						},
+						Options: SchemaModelOptions{},
+					},
+				},
+				Structs: []*SchemaStruct{
+					{
+						Name: "Money",
+						Fields: []*SchemaField{
+							{
+								Name:     "units",
+								WireName: "units",
+								Type:     &Integer{},
+							},
+							{
+								Name:     "currency",
+								WireName: "currency",
+								Type:     &Enum{T: &SchemaEnum{Name: "Currency"}},
+							},
+						},
+					},
+				},
+				Enums: []*SchemaEnum{
+					{
+						Name: "Currency",
+						Values: []*SchemaEnumValue{
+							{Name: "usd", WireValue: "USD"},
+							{Name: "eur", WireValue: "EUR"},
+						},
+					},
+				},
+				Options: SchemaOptions{},
+			},
+		},
+		{
+			name: "packages",
+			want: &Schema{
+				Models: []*SchemaModel{
+					{
+						Name: "Invoice",
+						Fields: []*SchemaField{
+							{
+								Name:     "total",
+								WireName: "total",
+								Type:     &Struct{T: &SchemaStruct{Name: "Money", Package: "billing"}},
+							},
+							{
+								Name:     "tip",
+								WireName: "tip",
+								Type:     &Struct{T: &SchemaStruct{Name: "Money"}},
+							},
+						},
+						Options: SchemaModelOptions{},
+					},
+				},
+				Structs: []*SchemaStruct{
+					{
+						Name: "Money",
+						Fields: []*SchemaField{
+							{
+								Name:     "amount",
+								WireName: "amount",
+								Type:     &String{},
+							},
+						},
+					},
+					{
+						Name:    "Money",
+						Package: "billing",
+						Fields: []*SchemaField{
+							{
+								Name:     "units",
+								WireName: "units",
+								Type:     &Integer{},
+							},
+							{
+								Name:     "currency",
+								WireName: "currency",
+								Type:     &Enum{T: &SchemaEnum{Name: "Currency", Package: "billing"}},
+							},
+						},
+					},
+				},
+				Enums: []*SchemaEnum{
+					{
+						Name:    "Currency",
+						Package: "billing",
+						Values: []*SchemaEnumValue{
+							{Name: "usd", WireValue: "USD"},
+						},
+					},
+				},
+				Options: SchemaOptions{},
+			},
+		},
+		{
+			name:    "err_package_unknown",
+			wantErr: true,
+		},
+		{
+			name:    "err_package_duplicate",
+			wantErr: true,
+		},
+		{
+			name: "field_options",
+			want: &Schema{
+				Models: []*SchemaModel{
+					{
+						Name: "User",
+						Fields: []*SchemaField{
+							{
+								Name:     "name",
+								WireName: "displayName",
+								Comment:  "The name.",
+								Type:     &String{},
+								Options: SchemaFieldOptions{
+									"firestore": {"name": "displayName"},
+									"":          {"deprecated": "true"},
+								},
+								Deprecated: true,
+							},
+						},
+						Options: SchemaModelOptions{},
+					},
+				},
+				Options: SchemaOptions{},
+			},
+		},
+		{
+			name:    "err_field_options",
+			wantErr: true,
+		},
+		{
+			name: "field_naming",
+			want: &Schema{
+				Models: []*SchemaModel{
+					{
+						Name: "User",
+						Fields: []*SchemaField{
+							{
+								Name:     "display_name",
+								WireName: "displayName",
+								Type:     &String{},
+							},
+							{
+								Name:     "legacy",
+								WireName: "_legacy",
+								Type:     &String{},
+								Options: SchemaFieldOptions{
+									"firestore": {"name": "_legacy"},
+								},
+							},
+						},
+						Options: SchemaModelOptions{
+							"firestore": {"naming": "verbatim"},
+						},
+					},
+				},
+				Structs: []*SchemaStruct{
+					{
+						Name: "Address",
+						Fields: []*SchemaField{
+							{
+								Name:     "zip_code",
+								WireName: "zip_code",
+								Type:     &String{},
+							},
+						},
+					},
+				},
+				Options: SchemaOptions{
+					"firestore": {"naming": "snake"},
+				},
+			},
+		},
+		{
+			name: "presence",
+			want: &Schema{
+				Models: []*SchemaModel{
+					{
+						Name: "User",
+						Fields: []*SchemaField{
+							{
+								Name:     "name",
+								WireName: "name",
+								Type:     &String{},
+								Presence: PresenceRequired,
+							},
+							{
+								Name:     "age",
+								WireName: "age",
+								Type:     &Integer{},
+								Presence: PresenceOptional,
+							},
+							{
+								Name:     "nickname",
+								WireName: "nickname",
+								Type:     &String{},
+								Nullable: true,
+							},
+							{
+								Name:     "deleted_at",
+								WireName: "deletedAt",
+								Type:     &Timestamp{},
+								Presence: PresenceRequired,
+								Nullable: true,
+							},
+						},
+						Options: SchemaModelOptions{},
+					},
+				},
+				Options: SchemaOptions{},
+			},
+		},
+		{
+			name: "defaults",
+			want: &Schema{
+				Models: []*SchemaModel{
+					{
+						Name: "Job",
+						Fields: []*SchemaField{
+							{
+								Name:     "retries",
+								WireName: "retries",
+								Type:     &Integer{},
+								Default:  int64(3),
+							},
+							{
+								Name:     "backoff",
+								WireName: "backoff",
+								Type:     &Double{},
+								Default:  -1.5,
+							},
+							{
+								Name:     "enabled",
+								WireName: "enabled",
+								Type:     &Boolean{},
+								Default:  true,
+							},
+							{
+								Name:     "name",
+								WireName: "label",
+								Type:     &String{},
+								Default:  "job",
+								Options: SchemaFieldOptions{
+									"firestore": {"name": "label"},
+								},
+							},
+							{
+								Name:     "direction",
+								WireName: "direction",
+								Type:     &Enum{T: &SchemaEnum{Name: "Direction"}},
+								Default:  &SchemaEnumValue{Name: "down"},
+							},
+						},
+						Options: SchemaModelOptions{},
+					},
+				},
+				Enums: []*SchemaEnum{
+					{
+						Name: "Direction",
+						Values: []*SchemaEnumValue{
+							{Name: "up", WireValue: "UP"},
+							{Name: "down", WireValue: "DOWN"},
+						},
+					},
+				},
+				Options: SchemaOptions{},
+			},
+		},
+		{
+			name: "enum_values",
+			want: &Schema{
+				Enums: []*SchemaEnum{
+					{
+						Name: "Direction",
+						Values: []*SchemaEnumValue{
+							{Name: "left", WireValue: "L"},
+							{Name: "right", WireValue: "RIGHT", Aliases: []interface{}{"R"}, Deprecated: true},
+						},
+					},
+					{
+						Name:    "Priority",
+						Backing: EnumBackingInteger,
+						Values: []*SchemaEnumValue{
+							{Name: "low", WireValue: int64(1)},
+							{Name: "high", WireValue: int64(2), Aliases: []interface{}{int64(3)}},
+						},
+					},
+				},
+				Options: SchemaOptions{},
+			},
+		},
+		{
+			name: "constraints",
+			want: &Schema{
+				Models: []*SchemaModel{
+					{
+						Name: "User",
+						Fields: []*SchemaField{
+							{
+								Name:     "name",
+								WireName: "name",
+								Type:     &String{},
+								Constraints: []*SchemaConstraint{
+									{Kind: ConstraintMinLength, Limit: 1},
+									{Kind: ConstraintMaxLength, Limit: 32},
+									{Kind: ConstraintPattern, Pattern: "^[a-z]+$"},
+								},
+								Options: SchemaFieldOptions{
+									"": {"min_length": "1", "max_length": "32", "pattern": "^[a-z]+$"},
+								},
+							},
+							{
+								Name:     "score",
+								WireName: "score",
+								Type:     &Double{},
+								Constraints: []*SchemaConstraint{
+									{Kind: ConstraintMin, Limit: -0.5},
+									{Kind: ConstraintMax, Limit: 10},
+								},
+								Options: SchemaFieldOptions{
+									"": {"min": "-0.5", "max": "10"},
+								},
+							},
+							{
+								Name:     "tags",
+								WireName: "tags",
+								Type:     &Array{T: &String{}},
+								Constraints: []*SchemaConstraint{
+									{Kind: ConstraintNonEmpty},
+									{Kind: ConstraintMinItems, Limit: 1},
+									{Kind: ConstraintMaxItems, Limit: 5},
+								},
+								Options: SchemaFieldOptions{
+									"": {"non_empty": "true", "min_items": "1", "max_items": "5"},
+								},
+							},
+						},
+						Options: SchemaModelOptions{},
+					},
+				},
+				Options: SchemaOptions{},
+			},
+		},
+		{
+			name: "union",
+			want: &Schema{
+				Models: []*SchemaModel{
+					{
+						Name: "Order",
+						Fields: []*SchemaField{
+							{
+								Name:     "payment",
+								WireName: "payment",
+								Type:     &Union{T: &SchemaUnion{Name: "Payment"}},
+							},
+						},
+						Options: SchemaModelOptions{},
+					},
+				},
+				Structs: []*SchemaStruct{
+					{
+						Name: "Card",
+						Fields: []*SchemaField{
+							{Name: "number", WireName: "number", Type: &String{}},
+						},
+					},
+					{
+						Name: "BankTransfer",
+						Fields: []*SchemaField{
+							{Name: "iban", WireName: "iban", Type: &String{}},
+						},
+					},
+				},
+				Unions: []*SchemaUnion{
+					{
+						Name:          "Payment",
+						Comment:       "A Payment is paid by card or by bank transfer.",
+						Discriminator: "type",
+						Members: []*SchemaField{
+							{
+								Name:     "card",
+								WireName: "card",
+								Type:     &Struct{T: &SchemaStruct{Name: "Card"}},
+							},
+							{
+								Name:     "bank_transfer",
+								WireName: "bank",
+								Type:     &Struct{T: &SchemaStruct{Name: "BankTransfer"}},
+								Options: SchemaFieldOptions{
+									"firestore": {"name": "bank"},
+								},
+							},
+						},
+						Options: SchemaModelOptions{},
+					},
+				},
+				Options: SchemaOptions{},
+			},
+		},
+		{
+			name: "extends",
+			want: &Schema{
+				Models: []*SchemaModel{
+					{
+						Name:    "Invoice",
+						Extends: []*SchemaStruct{{Name: "Audited"}},
+						Fields: []*SchemaField{
+							{
+								Name:     "owner",
+								WireName: "owner",
+								Type:     &String{},
+								Origin:   &SchemaStruct{Name: "Audited"},
+							},
+							{
+								Name:     "updated_at",
+								WireName: "updatedAt",
+								Type:     &Timestamp{},
+								Origin:   &SchemaStruct{Name: "Audited"},
+							},
+							{
+								Name:     "total",
+								WireName: "total",
+								Type:     &Integer{},
 							},
 						},
 						Options: SchemaModelOptions{},
 					},
 				},
+				Structs: []*SchemaStruct{
+					{
+						Name: "Owned",
+						Fields: []*SchemaField{
+							{Name: "owner", WireName: "owner", Type: &String{}},
+						},
+					},
+					{
+						Name:    "Audited",
+						Extends: []*SchemaStruct{{Name: "Owned"}},
+						Fields: []*SchemaField{
+							{
+								Name:     "owner",
+								WireName: "owner",
+								Type:     &String{},
+								Origin:   &SchemaStruct{Name: "Owned"},
+							},
+							{
+								Name:     "updated_at",
+								WireName: "updatedAt",
+								Type:     &Timestamp{},
+							},
+						},
+					},
+				},
+				Options: SchemaOptions{},
+			},
+		},
+		{
+			name: "aliases",
+			want: &Schema{
+				Models: []*SchemaModel{
+					{
+						Name: "User",
+						Fields: []*SchemaField{
+							{
+								Name:     "email",
+								WireName: "email",
+								Type:     &String{},
+								Alias:    &SchemaAlias{Name: "WorkEmail"},
+								Constraints: []*SchemaConstraint{
+									{Kind: ConstraintPattern, Pattern: "^[^@]+@[^@]+$"},
+									{Kind: ConstraintMaxLength, Limit: 254},
+									{Kind: ConstraintMinLength, Limit: 3},
+								},
+								Options: SchemaFieldOptions{
+									"": {"min_length": "3"},
+								},
+							},
+						},
+						Options: SchemaModelOptions{},
+					},
+				},
+				Aliases: []*SchemaAlias{
+					{
+						Name:    "Email",
+						Comment: "Email is an email address.",
+						Type:    &String{},
+						Constraints: []*SchemaConstraint{
+							{Kind: ConstraintPattern, Pattern: "^[^@]+@[^@]+$"},
+						},
+						Options: SchemaFieldOptions{
+							"": {"pattern": "^[^@]+@[^@]+$"},
+						},
+					},
+					{
+						Name: "WorkEmail",
+						Type: &String{},
+						Constraints: []*SchemaConstraint{
+							{Kind: ConstraintPattern, Pattern: "^[^@]+@[^@]+$"},
+							{Kind: ConstraintMaxLength, Limit: 254},
+						},
+						Options: SchemaFieldOptions{
+							"": {"max_length": "254"},
+						},
+					},
+				},
+				Options: SchemaOptions{},
+			},
+		},
+		{
+			name: "map_keys",
+			want: &Schema{
+				Enums: []*SchemaEnum{
+					{
+						Name: "Color",
+						Values: []*SchemaEnumValue{
+							{Name: "red", WireValue: "RED"},
+						},
+					},
+				},
+				Models: []*SchemaModel{
+					{
+						Name: "Palette",
+						Fields: []*SchemaField{
+							{
+								Name:     "counts",
+								WireName: "counts",
+								Type: &Map{
+									K: &Enum{T: &SchemaEnum{Name: "Color"}},
+									T: &Integer{},
+								},
+							},
+							{
+								Name:     "names",
+								WireName: "names",
+								Type: &Map{
+									T: &Enum{T: &SchemaEnum{Name: "Color"}},
+								},
+							},
+						},
+						Options: SchemaModelOptions{},
+					},
+				},
+				Options: SchemaOptions{},
+			},
+		},
+		{
+			name: "reserved",
+			want: &Schema{
+				Enums: []*SchemaEnum{
+					{
+						Name: "Color",
+						Values: []*SchemaEnumValue{
+							{Name: "red", WireValue: "RED"},
+						},
+						Reserved: []interface{}{"blue", "G"},
+					},
+					{
+						Name:    "Priority",
+						Backing: EnumBackingInteger,
+						Values: []*SchemaEnumValue{
+							{Name: "low", WireValue: int64(1)},
+						},
+						Reserved: []interface{}{int64(2), "medium"},
+					},
+				},
+				Models: []*SchemaModel{
+					{
+						Name: "User",
+						Fields: []*SchemaField{
+							{
+								Name:       "name",
+								WireName:   "name",
+								Type:       &String{},
+								Options:    SchemaFieldOptions{"": {"deprecated": "true"}},
+								Deprecated: true,
+							},
+						},
+						Reserved: []string{"nickname"},
+						Options:  SchemaModelOptions{},
+					},
+				},
+				Structs: []*SchemaStruct{
+					{
+						Name: "Owned",
+						Fields: []*SchemaField{
+							{Name: "owner", WireName: "owner", Comment: "The name of the owner, which is unique.", Type: &String{}},
+						},
+						Reserved: []string{"owner_id"},
+					},
+				},
 				Options: SchemaOptions{},
 			},
 		},
+		{
+			name:    "err_presence_collection",
+			wantErr: true,
+		},
+		{
+			name:    "err_field_naming",
+			wantErr: true,
+		},
+		{
+			name:    "err_wire_name_collision",
+			wantErr: true,
+		},
+		{
+			name:    "err_import_cycle",
+			wantErr: true,
+		},
+		{
+			name:    "err_import_missing",
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -450,7 +1113,381 @@ func TestParseSchema(t *testing.T) {
 				return
 			}
 
-			assert.DeepEqual(t, got, tt.want)
+			assert.DeepEqual(t, got, tt.want, cmpopts.IgnoreTypes(Position{}), cmpopts.IgnoreFields(Schema{}, "Files"))
 		})
 	}
 }
+
+func TestParseSchemaDiagnostics(t *testing.T) {
+	_, err := ParseSchemaFiles(path.Join("testfixtures", "schema", "err_multiple.firemodel"))
+	diagnostics, ok := err.(Diagnostics)
+	if !ok {
+		t.Fatalf("expected Diagnostics, got %v", err)
+	}
+
+	filename := path.Join("testfixtures", "schema", "err_multiple.firemodel")
+	want := []string{
+		filename + ":2:10: error: invalid path option (must be even number of components) \"users\"",
+		filename + ":5:8: error: can't name struct Model, Model is a reserved word.",
+		filename + ":8:3: error: can't use models as field types (got SomeModel); please use reference, collection or switch model to struct instead",
+		filename + ":9:9: error: can't use models as field types (got SomeModel); please use reference, collection or switch model to struct instead",
+		filename + ":10:13: error: invalid generic type Unknown in reference<Unknown> (must be a model type)",
+		filename + ":11:3: error: invalid type: Unknown",
+	}
+	var got []string
+	for _, d := range diagnostics {
+		got = append(got, d.Error())
+	}
+	assert.DeepEqual(t, got, want)
+}
+
+func TestParseSchemaDefaults(t *testing.T) {
+	_, err := ParseSchemaFiles(path.Join("testfixtures", "schema", "err_defaults.firemodel"))
+	diagnostics, ok := err.(Diagnostics)
+	if !ok {
+		t.Fatalf("expected Diagnostics, got %v", err)
+	}
+
+	filename := path.Join("testfixtures", "schema", "err_defaults.firemodel")
+	want := []string{
+		filename + ":6:21: error: invalid default value \"three\" for integer field retries",
+		filename + ":7:25: error: invalid default value left for field direction (Direction has no value left)",
+		filename + ":8:26: error: can't set a default value on optional or nullable field name",
+		filename + ":9:23: error: can't set a default value on timestamp field created",
+	}
+	var got []string
+	for _, d := range diagnostics {
+		got = append(got, d.Error())
+	}
+	assert.DeepEqual(t, got, want)
+}
+
+func TestParseSchemaEnumValues(t *testing.T) {
+	_, err := ParseSchemaFiles(path.Join("testfixtures", "schema", "err_enum_values.firemodel"))
+	diagnostics, ok := err.(Diagnostics)
+	if !ok {
+		t.Fatalf("expected Diagnostics, got %v", err)
+	}
+
+	filename := path.Join("testfixtures", "schema", "err_enum_values.firemodel")
+	want := []string{
+		filename + ":3:3: error: enum values left and right are both stored as \"L\"",
+		filename + ":4:8: error: invalid value 1 for up (enum Direction is stored as string)",
+		filename + ":5:9: error: unknown enum value option color (must be alias or deprecated)",
+		filename + ":9:3: error: value low of integer enum Priority must be set explicitly (e.g. low = 1)",
+		filename + ":10:10: error: invalid value \"2\" for high (enum Priority is stored as integer)",
+		filename + ":13:6: error: invalid type boolean for enum Flag (must be string or integer)",
+	}
+	var got []string
+	for _, d := range diagnostics {
+		got = append(got, d.Error())
+	}
+	assert.DeepEqual(t, got, want)
+}
+
+func TestParseSchemaConstraints(t *testing.T) {
+	_, err := ParseSchemaFiles(path.Join("testfixtures", "schema", "err_constraints.firemodel"))
+	diagnostics, ok := err.(Diagnostics)
+	if !ok {
+		t.Fatalf("expected Diagnostics, got %v", err)
+	}
+
+	filename := path.Join("testfixtures", "schema", "err_constraints.firemodel")
+	want := []string{
+		filename + ":2:16: error: can't use min_length on integer field age",
+		filename + ":3:16: error: invalid pattern for field name: error parsing regexp: missing closing ]: `[a-z`",
+		filename + ":4:3: error: min of field score is greater than its max",
+		filename + ":5:20: error: invalid max for field retries: 2.5 is not an integer",
+		filename + ":6:23: error: invalid max_items for field tags: -1 is negative",
+	}
+	var got []string
+	for _, d := range diagnostics {
+		got = append(got, d.Error())
+	}
+	assert.DeepEqual(t, got, want)
+}
+
+func TestParseSchemaUnions(t *testing.T) {
+	_, err := ParseSchemaFiles(path.Join("testfixtures", "schema", "err_union.firemodel"))
+	diagnostics, ok := err.(Diagnostics)
+	if !ok {
+		t.Fatalf("expected Diagnostics, got %v", err)
+	}
+
+	filename := path.Join("testfixtures", "schema", "err_union.firemodel")
+	want := []string{
+		filename + ":7:3: error: union members card and other_card are both of type Card",
+		filename + ":8:3: error: invalid type string for union member note (must be a struct type)",
+		filename + ":9:3: error: union member optional_card can't be required, optional, nullable or have a default value",
+		filename + ":14:3: error: union member card is stored as card, which is the discriminator of union Tagged",
+		filename + ":17:7: error: union Empty must have at least one member",
+		filename + ":20:3: error: union member money is of type billing.Money, which must be declared in the package of union Foreign",
+	}
+	var got []string
+	for _, d := range diagnostics {
+		got = append(got, d.Error())
+	}
+	assert.DeepEqual(t, got, want)
+}
+
+func TestParseSchemaExtends(t *testing.T) {
+	_, err := ParseSchemaFiles(path.Join("testfixtures", "schema", "err_extends.firemodel"))
+	diagnostics, ok := err.(Diagnostics)
+	if !ok {
+		t.Fatalf("expected Diagnostics, got %v", err)
+	}
+
+	filename := path.Join("testfixtures", "schema", "err_extends.firemodel")
+	want := []string{
+		filename + ":11:30: error: Owned is extended more than once",
+		filename + ":11:37: error: can't extend Color (must be a struct type)",
+		filename + ":12:3: error: field owner conflicts with field owner inherited from Owned in Invoice",
+		filename + ":15:7: error: field owner inherited from Shared conflicts with field owner inherited from Owned in Document",
+		filename + ":17:8: error: struct Left extends itself",
+	}
+	var got []string
+	for _, d := range diagnostics {
+		got = append(got, d.Error())
+	}
+	assert.DeepEqual(t, got, want)
+}
+
+func TestParseSchemaAliases(t *testing.T) {
+	_, err := ParseSchemaFiles(path.Join("testfixtures", "schema", "err_aliases.firemodel"))
+	diagnostics, ok := err.(Diagnostics)
+	if !ok {
+		t.Fatalf("expected Diagnostics, got %v", err)
+	}
+
+	filename := path.Join("testfixtures", "schema", "err_aliases.firemodel")
+	want := []string{
+		filename + ":5:13: error: can't alias Address (must be a primitive type)",
+		filename + ":7:6: error: type Loop refers to itself",
+		filename + ":9:23: error: can't use pattern on integer type Count",
+		filename + ":11:20: error: invalid type: Name",
+		filename + ":14:17: error: can't use min_length on Count field visits",
+	}
+	var got []string
+	for _, d := range diagnostics {
+		got = append(got, d.Error())
+	}
+	assert.DeepEqual(t, got, want)
+}
+
+func TestParseSchemaMapKeys(t *testing.T) {
+	_, err := ParseSchemaFiles(path.Join("testfixtures", "schema", "err_map_keys.firemodel"))
+	diagnostics, ok := err.(Diagnostics)
+	if !ok {
+		t.Fatalf("expected Diagnostics, got %v", err)
+	}
+
+	filename := path.Join("testfixtures", "schema", "err_map_keys.firemodel")
+	want := []string{
+		filename + ":4:7: error: invalid key type Priority in map<Priority, string> (must be string or an enum stored as strings)",
+		filename + ":5:7: error: invalid key type integer in map<integer, string> (must be string or an enum stored as strings)",
+		filename + ":6:17: error: too many type parameters in array<string, string> (only maps have a key type)",
+		filename + ":7:15: error: invalid type: Unknown",
+	}
+	var got []string
+	for _, d := range diagnostics {
+		got = append(got, d.Error())
+	}
+	assert.DeepEqual(t, got, want)
+}
+
+func TestParseSchemaReserved(t *testing.T) {
+	_, err := ParseSchemaFiles(path.Join("testfixtures", "schema", "err_reserved.firemodel"))
+	diagnostics, ok := err.(Diagnostics)
+	if !ok {
+		t.Fatalf("expected Diagnostics, got %v", err)
+	}
+
+	filename := path.Join("testfixtures", "schema", "err_reserved.firemodel")
+	want := []string{
+		filename + ":2:30: error: invalid reserved value 3 for enum Color (must be a name or a stored value)",
+		filename + ":3:3: error: enum value blue is reserved in Color",
+		filename + ":4:3: error: enum value green is stored as \"G\", which is reserved in Color",
+		filename + ":5:3: error: enum value cyan is stored as \"Y\", which is reserved in Color",
+		filename + ":10:3: error: enum value medium is reserved in Priority",
+		filename + ":11:3: error: enum value high is stored as 2, which is reserved in Priority",
+		filename + ":18:7: error: field owner inherited from Owned is reserved in User",
+		filename + ":19:47: error: invalid reserved field name 7 (must be a string)",
+		filename + ":20:3: error: field nick_name is reserved in User",
+		filename + ":21:3: error: field title is stored as oldTitle, which is reserved in User",
+		filename + ":25:12: error: can't reserve names in union Payment",
+	}
+	var got []string
+	for _, d := range diagnostics {
+		got = append(got, d.Error())
+	}
+	assert.DeepEqual(t, got, want)
+}
+
+func TestParseSchemaLint(t *testing.T) {
+	_, err := ParseSchemaFiles(path.Join("testfixtures", "schema", "err_lint.firemodel"))
+	diagnostics, ok := err.(Diagnostics)
+	if !ok {
+		t.Fatalf("expected Diagnostics, got %v", err)
+	}
+
+	filename := path.Join("testfixtures", "schema", "err_lint.firemodel")
+	want := []string{
+		filename + ":1:7: error: model Grid is stored in __grids__, which Firestore reserves (IDs matching __.*__)",
+		filename + ":4:3: error: field rows has an array directly inside an array, which Firestore doesn't support (wrap the inner array in a struct)",
+		filename + ":5:3: error: field labels has an array directly inside an array, which Firestore doesn't support (wrap the inner array in a struct)",
+		filename + ":6:3: error: field meta is stored as __id__, which Firestore reserves (keys matching __.*__)",
+		filename + ":13:7: error: union Node is discriminated by __kind__, which Firestore reserves (keys matching __.*__)",
+	}
+	var got []string
+	for _, d := range diagnostics {
+		got = append(got, d.Error())
+	}
+	assert.DeepEqual(t, got, want)
+}
+
+func TestParseSchemaWarnings(t *testing.T) {
+	schema, err := ParseSchemaFiles(path.Join("testfixtures", "schema", "lint_depth.firemodel"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filename := path.Join("testfixtures", "schema", "lint_depth.firemodel")
+	want := []string{
+		filename + ":8:3: warning: field deep of Forest nests maps and arrays 21 levels deep (Firestore allows at most 20)",
+	}
+	var got []string
+	for _, d := range schema.Warnings {
+		got = append(got, d.Error())
+	}
+	assert.DeepEqual(t, got, want)
+}
+
+func TestParseSchemaSyntaxError(t *testing.T) {
+	_, err := ParseSchemaFiles(path.Join("testfixtures", "schema", "syntax_nonsense_2.firemodel"))
+	diagnostics, ok := err.(Diagnostics)
+	if !ok {
+		t.Fatalf("expected Diagnostics, got %v", err)
+	}
+	assert.Equal(t, len(diagnostics), 1)
+	assert.Equal(t, diagnostics[0].Pos.Line, 1)
+	assert.Equal(t, diagnostics[0].Severity, SeverityError)
+}
+
+func TestParseSchemaImportCycle(t *testing.T) {
+	_, err := ParseSchemaFiles(path.Join("testfixtures", "schema", "err_import_cycle.firemodel"))
+	diagnostics, ok := err.(Diagnostics)
+	if !ok {
+		t.Fatalf("expected Diagnostics, got %v", err)
+	}
+
+	cycleA := path.Join("testfixtures", "schema", "imports", "cycle_a.firemodel")
+	cycleB := path.Join("testfixtures", "schema", "imports", "cycle_b.firemodel")
+	assert.Equal(t, len(diagnostics), 1)
+	assert.Equal(t, diagnostics[0].Error(), cycleB+":1:8: error: import cycle: "+cycleA+" -> "+cycleB+" -> "+cycleA)
+}
+
+func TestParseSchemaFiles(t *testing.T) {
+	schema, err := ParseSchemaFiles(path.Join("testfixtures", "schema", "imports.firemodel"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.DeepEqual(t, schema.Files, []string{
+		path.Join("testfixtures", "schema", "imports.firemodel"),
+		path.Join("testfixtures", "schema", "imports", "money.firemodel"),
+		path.Join("testfixtures", "schema", "imports", "currency.firemodel"),
+	})
+}
+
+func TestParseSchemaNames(t *testing.T) {
+	_, err := ParseSchemaFiles(path.Join("testfixtures", "schema", "err_names.firemodel"))
+	diagnostics, ok := err.(Diagnostics)
+	if !ok {
+		t.Fatalf("expected Diagnostics, got %v", err)
+	}
+
+	filename := path.Join("testfixtures", "schema", "err_names.firemodel")
+	want := []string{
+		filename + ":1:7: error: model Client collides with Client, which is generated for every package",
+		filename + ":10:3: error: fields fooBar and foo_bar are both named FooBar (declared at " + filename + ":9:3)",
+		filename + ":11:3: error: field created_at collides with the created_at field that firestore.autotimestamp adds to model Invoice",
+		filename + ":12:3: error: field updated collides with the updated_at field that firestore.autotimestamp adds to model Invoice",
+		filename + ":15:8: error: struct InvoicePath collides with InvoicePath, which is generated for model Invoice",
+		filename + ":19:8: error: struct invoice_path collides with struct InvoicePath (declared at " + filename + ":15:8)",
+		filename + ":25:3: error: enum values light_blue and LIGHT_BLUE are both named light_blue (declared at " + filename + ":24:3)",
+		filename + ":33:3: error: field base of struct Derived collides with the extended struct Base",
+		filename + ":34:3: error: field validate of struct Derived collides with the generated Validate method",
+		filename + ":46:3: error: member value of union Payment collides with the generated Value method",
+		filename + ":47:3: error: union member type is stored as type, which is the discriminator of union Payment",
+	}
+	var got []string
+	for _, d := range diagnostics {
+		got = append(got, d.Error())
+	}
+	assert.DeepEqual(t, got, want)
+}
+
+func TestParseSchemaKeywordNames(t *testing.T) {
+	schema, err := ParseSchemaFiles(path.Join("testfixtures", "schema", "keyword_names.firemodel"))
+	assert.NilError(t, err)
+
+	var got []string
+	for _, structType := range schema.Structs {
+		got = append(got, structType.Name)
+	}
+	for _, enum := range schema.Enums {
+		got = append(got, enum.Name)
+	}
+	for _, model := range schema.Models {
+		got = append(got, model.Name)
+	}
+	assert.DeepEqual(t, got, []string{"Type", "Extends", "Required", "Package", "Import"})
+}
+
+func TestParseSchemaCycles(t *testing.T) {
+	_, err := ParseSchemaFiles(path.Join("testfixtures", "schema", "err_cycles.firemodel"))
+	diagnostics, ok := err.(Diagnostics)
+	if !ok {
+		t.Fatalf("expected Diagnostics, got %v", err)
+	}
+
+	filename := path.Join("testfixtures", "schema", "err_cycles.firemodel")
+	want := []string{
+		filename + ":3:3: error: struct Node contains itself (Node.next stores Node); use a reference<T> or collection<T> to refer back to it",
+		filename + ":7:3: error: struct Tree contains itself (Tree.branches stores Branch, Branch.tree stores Tree); use a reference<T> or collection<T> to refer back to it",
+		filename + ":14:8: error: struct Leaf contains itself (Leaf extends Parent, Parent.leaves stores Leaf); use a reference<T> or collection<T> to refer back to it",
+		filename + ":32:3: error: struct Group contains itself (Group.shapes stores Shape, Shape.group stores Group); use a reference<T> or collection<T> to refer back to it",
+	}
+	var got []string
+	for _, d := range diagnostics {
+		got = append(got, d.Error())
+	}
+	assert.DeepEqual(t, got, want)
+}
+
+func TestSchemaDependencyGraph(t *testing.T) {
+	schema, err := ParseSchemaFiles(path.Join("testfixtures", "schema", "dependencies.firemodel"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	graph := schema.DependencyGraph()
+	var got []string
+	for _, decl := range graph.Declarations {
+		for _, dep := range graph.Dependencies(decl) {
+			got = append(got, fmt.Sprintf("%s -%s-> %s (%s)", declarationName(dep.From), dep.Kind, declarationName(dep.To), dep.Name))
+		}
+	}
+	want := []string{
+		"Folder -extends-> Owned ()",
+		"Folder -reference-> Folder (parent)",
+		"Folder -inline-> Color (labels)",
+		"Folder -inline-> Label (labels)",
+		"Folder -collection-> Folder (folders)",
+		"Owned -reference-> Folder (owner)",
+		"Label -inline-> Color (color)",
+		"Label -alias-> Hex (hex)",
+	}
+	assert.DeepEqual(t, got, want)
+	assert.Equal(t, len(graph.Dependents(schema.Enum("", "Color"))), 2)
+	assert.Equal(t, len(graph.InlineCycles()), 0)
+}
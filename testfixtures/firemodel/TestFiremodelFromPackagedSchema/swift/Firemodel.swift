@@ -0,0 +1,108 @@
+// DO NOT EDIT - Code generated by firemodel (dev).
+
+import Foundation
+import Pring
+
+@objc enum BillingInvoiceStatus: Int {
+    case draft
+    case paid
+}
+
+extension BillingInvoiceStatus: CustomDebugStringConvertible {
+    init?(firestoreValue value: Any?) {
+        guard let value = value as? String else {
+            return nil
+        }
+        switch value {
+        case "DRAFT":
+            self = .draft
+        case "PAID":
+            self = .paid
+        default:
+            return nil
+        }
+    }
+
+    var firestoreValue: String? {
+        switch self {
+        case .draft:
+            return "DRAFT"
+        case .paid:
+            return "PAID"
+        }
+    }
+
+    var debugDescription: String { return firestoreValue ?? "<INVALID>" }
+}
+
+@objcMembers class BillingAddress: Pring.Object {
+    var line1: String?
+    var city: String?
+}
+
+// A User is a customer.
+@objcMembers class User: Pring.Object {
+override class var path: String { return "users" }
+    dynamic var name: String?
+    dynamic var address: BillingAddress?
+    dynamic var latestInvoice: Pring.Reference<BillingInvoice> = .init()
+    dynamic var invoices: Pring.NestedCollection<BillingInvoice> = []
+
+    override func encode(_ key: String, value: Any?) -> Any? {
+        switch key {
+        case "address":
+            return self.address?.rawValue
+        default:
+            break
+        }
+        return nil
+    }
+
+    override func decode(_ key: String, value: Any?) -> Bool {
+        switch key {
+        case "address":
+          if let value = value as? [String: Any] {
+            self.address = BillingAddress(id: "\(0)", value: value)
+            return true
+          }
+        default:
+            break
+        }
+        return false
+    }
+}
+
+// An Invoice is a bill sent to a User.
+@objcMembers class BillingInvoice: Pring.Object {
+override class var path: String { return "invoices" }
+    dynamic var status: BillingInvoiceStatus?
+    dynamic var total: Int = 0
+    dynamic var billingAddress: BillingAddress?
+
+    override func encode(_ key: String, value: Any?) -> Any? {
+        switch key {
+        case "status":
+            return self.status?.firestoreValue
+        case "billingAddress":
+            return self.billingAddress?.rawValue
+        default:
+            break
+        }
+        return nil
+    }
+
+    override func decode(_ key: String, value: Any?) -> Bool {
+        switch key {
+        case "status":
+            self.status = BillingInvoiceStatus(firestoreValue: value)
+        case "billingAddress":
+          if let value = value as? [String: Any] {
+            self.billingAddress = BillingAddress(id: "\(0)", value: value)
+            return true
+          }
+        default:
+            break
+        }
+        return false
+    }
+}
@@ -0,0 +1,49 @@
+// DO NOT EDIT - Code generated by firemodel (dev).
+import { firestore } from 'firebase';
+
+// tslint:disable-next-line:no-namespace
+export namespace app {
+  type URL = string;
+
+  export interface IFile {
+    url: URL;
+    mimeType: string;
+    name: string;
+  }
+
+  /** A User is a customer. */
+  export interface IUser {
+    invoices: firestore.CollectionReference<billing.IInvoice>;
+    name?: string;
+    address?: billing.IAddress;
+    latestInvoice?: firestore.DocumentReference<billing.IInvoice>;
+
+    /** Record creation timestamp. */
+    createdAt?: firestore.Timestamp;
+    /** Record update timestamp. */
+    updatedAt?: firestore.Timestamp;
+  }
+
+  export namespace billing {
+    export enum InvoiceStatus {
+      draft = 'DRAFT',
+      paid = 'PAID',
+    }
+    export interface IAddress {
+      line1?: string;
+      city?: string;
+    }
+
+    /** An Invoice is a bill sent to a User. */
+    export interface IInvoice {
+      status?: billing.InvoiceStatus;
+      total?: number;
+      billingAddress?: billing.IAddress;
+
+      /** Record creation timestamp. */
+      createdAt?: firestore.Timestamp;
+      /** Record update timestamp. */
+      updatedAt?: firestore.Timestamp;
+    }
+  }
+}
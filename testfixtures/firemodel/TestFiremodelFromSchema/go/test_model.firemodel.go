@@ -45,6 +45,10 @@ type TestModel struct {
 	TestFile   *runtime.File            `firestore:"testFile,omitempty"`
 	Url        runtime.URL              `firestore:"url,omitempty"`
 	Nested     *TestStruct              `firestore:"nested,omitempty"`
+	// Stored under a legacy key.
+	LegacyName string `firestore:"LEGACY_name,omitempty"`
+	// Stored under a key that is a Swift keyword.
+	DefaultDirection TestEnum `firestore:"default,omitempty"`
 
 	// Creation timestamp.
 	CreatedAt time.Time `firestore:"createdAt"`
@@ -52,6 +56,32 @@ type TestModel struct {
 	UpdatedAt time.Time `firestore:"updatedAt"`
 }
 
+// Validate returns an error if the TestModel violates a validation constraint of the schema.
+func (m *TestModel) Validate() error {
+	for i, item := range m.Models {
+		if item == nil {
+			continue
+		}
+		if err := item.Validate(); err != nil {
+			return fmt.Errorf("models[%d].%s", i, err)
+		}
+	}
+	for i, item := range m.Models2 {
+		if item == nil {
+			continue
+		}
+		if err := item.Validate(); err != nil {
+			return fmt.Errorf("models2[%d].%s", i, err)
+		}
+	}
+	if m.Nested != nil {
+		if err := m.Nested.Validate(); err != nil {
+			return fmt.Errorf("nested.%s", err)
+		}
+	}
+	return nil
+}
+
 // TestModelPath returns the path to a particular TestModel in Firestore.
 func TestModelPath(userId string, testModelId string) string {
 	return fmt.Sprintf("users/%s/test_models/%s", userId, testModelId)
@@ -2,8 +2,23 @@
 
 package firemodel
 
+import "errors"
+
 type TestStruct struct {
 	Where    string   `firestore:"where,omitempty"`
 	HowMuch  int64    `firestore:"howMuch"`
 	SomeEnum TestEnum `firestore:"someEnum,omitempty"`
 }
+
+// NewTestStruct returns a new TestStruct with default values.
+func NewTestStruct() *TestStruct {
+	return &TestStruct{SomeEnum: TestEnum_LEFT}
+}
+
+// Validate returns an error if the TestStruct violates a validation constraint of the schema.
+func (m *TestStruct) Validate() error {
+	if m.HowMuch < 0 {
+		return errors.New("howMuch: must be >= 0")
+	}
+	return nil
+}
@@ -11,7 +11,6 @@ import (
 	"time"
 )
 
-//
 // Firestore document location: /timestamps/{test_timestamps_id}
 type TestTimestamps struct {
 
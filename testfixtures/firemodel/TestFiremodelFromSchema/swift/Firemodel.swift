@@ -3,6 +3,86 @@
 import Foundation
 import Pring
 
+// TestEmail is an email address.
+typealias TestEmail = String
+
+typealias TestCents = Int
+
+typealias TestWorkEmail = String
+
+typealias TestCreatedAt = Date
+
+// TestDirection is stored with explicit values.
+@objc enum TestDirection: Int {
+    case north
+    case south
+    // Replaced by south.
+    // Deprecated: kept to decode existing documents, don't store it in new ones.
+    case down
+}
+
+extension TestDirection: CustomDebugStringConvertible {
+    init?(firestoreValue value: Any?) {
+        guard let value = value as? String else {
+            return nil
+        }
+        switch value {
+        case "N":
+            self = .north
+        case "S", "SOUTH", "DOWN":
+            self = .south
+        case "D":
+            self = .down
+        default:
+            return nil
+        }
+    }
+
+    var firestoreValue: String? {
+        switch self {
+        case .north:
+            return "N"
+        case .south:
+            return "S"
+        case .down:
+            return "D"
+        }
+    }
+
+    var debugDescription: String { return firestoreValue ?? "<INVALID>" }
+}
+// TestPriority is stored as integers.
+@objc enum TestPriority: Int {
+    case low
+    case high
+}
+
+extension TestPriority: CustomDebugStringConvertible {
+    init?(firestoreValue value: Any?) {
+        guard let value = value as? Int else {
+            return nil
+        }
+        switch value {
+        case 1:
+            self = .low
+        case 2, 3:
+            self = .high
+        default:
+            return nil
+        }
+    }
+
+    var firestoreValue: Int? {
+        switch self {
+        case .low:
+            return 1
+        case .high:
+            return 2
+        }
+    }
+
+    var debugDescription: String { return firestoreValue.map { String($0) } ?? "<INVALID>" }
+}
 @objc enum TestEnum: Int {
     case left
     case right
@@ -46,9 +126,9 @@ extension TestEnum: CustomDebugStringConvertible {
 }
 
 @objcMembers class TestStruct: Pring.Object {
-    var where: String?
+    var `where`: String?
     var howMuch: Int = 0
-    var someEnum: TestEnum?
+    var someEnum: TestEnum? = .left
 
     override func encode(_ key: String, value: Any?) -> Any? {
         switch key {
@@ -69,6 +149,100 @@ extension TestEnum: CustomDebugStringConvertible {
         }
         return false
     }
+
+    // Returns a description of the first validation constraint that the object violates, if any.
+    func validate() -> String? {
+        do {
+            let v = self.howMuch
+            if v < 0 {
+                return "howMuch: must be >= 0"
+            }
+        }
+        return nil
+    }
+}
+
+@objcMembers class TestCard: Pring.Object {
+    var number: String?
+}
+
+@objcMembers class TestBankTransfer: Pring.Object {
+    var iban: String?
+}
+
+// TestOwned is shared by documents that have an owner.
+@objcMembers class TestOwned: Pring.Object {
+    var ownerId: String?
+    var version: Int = 1
+}
+
+@objcMembers class TestAudited: Pring.Object {
+    var updatedBy: String?
+
+    // Returns a description of the first validation constraint that the object violates, if any.
+    func validate() -> String? {
+        do {
+            let v = self.updatedBy ?? ""
+            if v.unicodeScalars.count < 1 {
+                return "updatedBy: length must be >= 1"
+            }
+        }
+        return nil
+    }
+}
+
+@objcMembers class TestLineItem: Pring.Object {
+    var updatedBy: String?
+    var sku: String?
+
+    // Returns a description of the first validation constraint that the object violates, if any.
+    func validate() -> String? {
+        do {
+            let v = self.updatedBy ?? ""
+            if v.unicodeScalars.count < 1 {
+                return "updatedBy: length must be >= 1"
+            }
+        }
+        return nil
+    }
+}
+
+// TestPayment is paid by card or by bank transfer.
+enum TestPayment {
+    // Paid by card.
+    case card(TestCard)
+    case bankTransfer(TestBankTransfer)
+}
+
+extension TestPayment {
+    init?(firestoreValue value: Any?) {
+        guard let value = value as? [String: Any], let member = value["kind"] as? String else {
+            return nil
+        }
+        switch member {
+        case "card":
+            guard let data = value["card"] as? [String: Any] else {
+                return nil
+            }
+            self = .card(TestCard(id: "card", value: data))
+        case "bankTransfer":
+            guard let data = value["bankTransfer"] as? [String: Any] else {
+                return nil
+            }
+            self = .bankTransfer(TestBankTransfer(id: "bankTransfer", value: data))
+        default:
+            return nil
+        }
+    }
+
+    var firestoreValue: [String: Any] {
+        switch self {
+        case .card(let member):
+            return ["kind": "card", "card": member.rawValue]
+        case .bankTransfer(let member):
+            return ["kind": "bankTransfer", "bankTransfer": member.rawValue]
+        }
+    }
 }
 
 // A Test is a test model.
@@ -102,12 +276,18 @@ override class var path: String { return "test_models" }
     dynamic var testFile: Pring.File?
     dynamic var url: URL?
     dynamic var nested: TestStruct?
+    // Stored under a legacy key.
+    dynamic var LEGACY_name: String?
+    // Stored under a key that is a Swift keyword.
+    dynamic var `default`: TestEnum?
     dynamic var nestedCollection: Pring.NestedCollection<TestModel> = []
 
     override func encode(_ key: String, value: Any?) -> Any? {
         switch key {
         case "direction":
             return self.direction?.firestoreValue
+        case "default":
+            return self.`default`?.firestoreValue
         case "models":
             return self.models?.map { $0.rawValue }
         case "models2":
@@ -126,6 +306,8 @@ override class var path: String { return "test_models" }
         switch key {
         case "direction":
             self.direction = TestEnum(firestoreValue: value)
+        case "default":
+            self.`default` = TestEnum(firestoreValue: value)
         case "models":
             self.models = (value as? [[String: Any]])?
                 .enumerated()
@@ -147,6 +329,483 @@ override class var path: String { return "test_models" }
         }
         return false
     }
+
+    // Returns a description of the first validation constraint that the object violates, if any.
+    func validate() -> String? {
+        do {
+            let v = self.models ?? []
+            for (i, item) in v.enumerated() {
+                if let err = item.validate() {
+                    return "models[\(i)].\(err)"
+                }
+            }
+        }
+        do {
+            let v = self.models2 ?? []
+            for (i, item) in v.enumerated() {
+                if let err = item.validate() {
+                    return "models2[\(i)].\(err)"
+                }
+            }
+        }
+        if let v = self.nested {
+            if let err = v.validate() {
+                return "nested.\(err)"
+            }
+        }
+        return nil
+    }
+}
+
+// TestSnakeCase is stored with snake_case keys.
+@objcMembers class TestSnakeCase: Pring.Object {
+    dynamic var display_name: String?
+    dynamic var logins: Int = 0
+}
+
+// TestPresence has fields with presence modifiers.
+@objcMembers class TestPresence: Pring.Object {
+    dynamic var name: String = ""
+    dynamic var age: Int = 0
+    dynamic var nickname: String?
+    dynamic var profile: TestStruct?
+    dynamic var previousProfile: TestStruct?
+    dynamic var labels: [String: String]?
+    dynamic var avatar: Data = Data()
+    dynamic var active: Bool = false
+
+    override func encode(_ key: String, value: Any?) -> Any? {
+        switch key {
+        case "profile":
+            return self.profile?.rawValue
+        case "previousProfile":
+            return self.previousProfile?.rawValue
+        default:
+            break
+        }
+        return nil
+    }
+
+    override func decode(_ key: String, value: Any?) -> Bool {
+        switch key {
+        case "profile":
+          if let value = value as? [String: Any] {
+            self.profile = TestStruct(id: "\(0)", value: value)
+            return true
+          }
+        case "previousProfile":
+          if let value = value as? [String: Any] {
+            self.previousProfile = TestStruct(id: "\(0)", value: value)
+            return true
+          }
+        default:
+            break
+        }
+        return false
+    }
+
+    // Returns a description of the first validation constraint that the object violates, if any.
+    func validate() -> String? {
+        if let v = self.profile {
+            if let err = v.validate() {
+                return "profile.\(err)"
+            }
+        }
+        if let v = self.previousProfile {
+            if let err = v.validate() {
+                return "previousProfile.\(err)"
+            }
+        }
+        return nil
+    }
+}
+
+// TestDefaults has fields with default values.
+@objcMembers class TestDefaults: Pring.Object {
+    dynamic var retries: Int = 3
+    dynamic var ratio: Float = 0.5
+    dynamic var enabled: Bool = true
+    dynamic var label: String = "it's new"
+    dynamic var homepage: URL? = URL(string: "https://example.com")
+    dynamic var direction: TestEnum? = .up
+
+    override func encode(_ key: String, value: Any?) -> Any? {
+        switch key {
+        case "direction":
+            return self.direction?.firestoreValue
+        default:
+            break
+        }
+        return nil
+    }
+
+    override func decode(_ key: String, value: Any?) -> Bool {
+        switch key {
+        case "direction":
+            self.direction = TestEnum(firestoreValue: value)
+        default:
+            break
+        }
+        return false
+    }
+}
+
+// TestEnums has fields with explicitly stored enums.
+@objcMembers class TestEnums: Pring.Object {
+    dynamic var direction: TestDirection? = .north
+    dynamic var priority: TestPriority? = .high
+    dynamic var priorities: [TestPriority]?
+
+    override func encode(_ key: String, value: Any?) -> Any? {
+        switch key {
+        case "direction":
+            return self.direction?.firestoreValue
+        case "priority":
+            return self.priority?.firestoreValue
+        case "priorities":
+            return self.priorities?.map { $0.firestoreValue }
+        default:
+            break
+        }
+        return nil
+    }
+
+    override func decode(_ key: String, value: Any?) -> Bool {
+        switch key {
+        case "direction":
+            self.direction = TestDirection(firestoreValue: value)
+        case "priority":
+            self.priority = TestPriority(firestoreValue: value)
+        case "priorities":
+            self.priorities = (value as? [Int])?.compactMap { TestPriority(firestoreValue: $0) }
+			return true
+        default:
+            break
+        }
+        return false
+    }
+}
+
+// TestValidation has fields with validation constraints.
+@objcMembers class TestValidation: Pring.Object {
+    dynamic var name: String = ""
+    dynamic var age: Int = 0
+    dynamic var ratio: Float = 0
+    dynamic var tags: [String]?
+    dynamic var labels: [String: String] = [:]
+    dynamic var nested: TestStruct?
+    dynamic var nestedList: [TestStruct]?
+
+    override func encode(_ key: String, value: Any?) -> Any? {
+        switch key {
+        case "nestedList":
+            return self.nestedList?.map { $0.rawValue }
+        case "nested":
+            return self.nested?.rawValue
+        default:
+            break
+        }
+        return nil
+    }
+
+    override func decode(_ key: String, value: Any?) -> Bool {
+        switch key {
+        case "nestedList":
+            self.nestedList = (value as? [[String: Any]])?
+                .enumerated()
+                .map { TestStruct(id: "nestedList.\($0.offset)", value: $0.element) }
+        case "nested":
+          if let value = value as? [String: Any] {
+            self.nested = TestStruct(id: "\(0)", value: value)
+            return true
+          }
+        default:
+            break
+        }
+        return false
+    }
+
+    // Returns a description of the first validation constraint that the object violates, if any.
+    func validate() -> String? {
+        do {
+            let v = self.name
+            if v.unicodeScalars.count < 1 {
+                return "name: length must be >= 1"
+            }
+            if v.unicodeScalars.count > 64 {
+                return "name: length must be <= 64"
+            }
+            if v.range(of: "^[a-z]+$", options: .regularExpression) == nil {
+                return "name: must match ^[a-z]+$"
+            }
+        }
+        do {
+            let v = self.age
+            if v < 0 {
+                return "age: must be >= 0"
+            }
+            if v > 150 {
+                return "age: must be <= 150"
+            }
+        }
+        do {
+            let v = self.ratio
+            if v < -1.5 {
+                return "ratio: must be >= -1.5"
+            }
+            if v > 1.5 {
+                return "ratio: must be <= 1.5"
+            }
+        }
+        if let v = self.tags {
+            if v.isEmpty {
+                return "tags: must not be empty"
+            }
+            if v.count > 10 {
+                return "tags: size must be <= 10"
+            }
+        }
+        do {
+            let v = self.labels
+            if v.isEmpty {
+                return "labels: must not be empty"
+            }
+        }
+        if let v = self.nested {
+            if let err = v.validate() {
+                return "nested.\(err)"
+            }
+        }
+        do {
+            let v = self.nestedList ?? []
+            for (i, item) in v.enumerated() {
+                if let err = item.validate() {
+                    return "nestedList[\(i)].\(err)"
+                }
+            }
+        }
+        return nil
+    }
+}
+
+// TestUnions has fields that hold unions.
+@objcMembers class TestUnions: Pring.Object {
+    var payment: TestPayment?
+    var lastPayment: TestPayment?
+    var payments: [TestPayment]?
+
+    override func encode(_ key: String, value: Any?) -> Any? {
+        switch key {
+        case "payment":
+            return self.payment?.firestoreValue
+        case "lastPayment":
+            return self.lastPayment?.firestoreValue
+        case "payments":
+            return self.payments?.map { $0.firestoreValue }
+        default:
+            break
+        }
+        return nil
+    }
+
+    override func decode(_ key: String, value: Any?) -> Bool {
+        switch key {
+        case "payment":
+            self.payment = TestPayment(firestoreValue: value)
+            return true
+        case "lastPayment":
+            self.lastPayment = TestPayment(firestoreValue: value)
+            return true
+        case "payments":
+            self.payments = (value as? [Any])?.compactMap { TestPayment(firestoreValue: $0) }
+            return true
+        default:
+            break
+        }
+        return false
+    }
+}
+
+// TestInvoice extends shared structs.
+@objcMembers class TestInvoice: Pring.Object {
+    dynamic var ownerId: String?
+    dynamic var version: Int = 1
+    dynamic var updatedBy: String?
+    dynamic var total: Int = 0
+    dynamic var items: [TestLineItem]?
+
+    override func encode(_ key: String, value: Any?) -> Any? {
+        switch key {
+        case "items":
+            return self.items?.map { $0.rawValue }
+        default:
+            break
+        }
+        return nil
+    }
+
+    override func decode(_ key: String, value: Any?) -> Bool {
+        switch key {
+        case "items":
+            self.items = (value as? [[String: Any]])?
+                .enumerated()
+                .map { TestLineItem(id: "items.\($0.offset)", value: $0.element) }
+        default:
+            break
+        }
+        return false
+    }
+
+    // Returns a description of the first validation constraint that the object violates, if any.
+    func validate() -> String? {
+        do {
+            let v = self.updatedBy ?? ""
+            if v.unicodeScalars.count < 1 {
+                return "updatedBy: length must be >= 1"
+            }
+        }
+        do {
+            let v = self.items ?? []
+            for (i, item) in v.enumerated() {
+                if let err = item.validate() {
+                    return "items[\(i)].\(err)"
+                }
+            }
+        }
+        return nil
+    }
+}
+
+// TestAliases has fields declared with type aliases.
+@objcMembers class TestAliases: Pring.Object {
+    dynamic var email: TestEmail?
+    dynamic var workEmail: TestWorkEmail?
+    // Checked against the pattern of TestEmail and its own.
+    dynamic var companyEmail: TestEmail?
+    dynamic var price: TestCents = 100
+    dynamic var tags: [String]?
+    dynamic var created: TestCreatedAt?
+
+    // Returns a description of the first validation constraint that the object violates, if any.
+    func validate() -> String? {
+        do {
+            let v = self.email ?? ""
+            if v.range(of: "^[^@]+@[^@]+$", options: .regularExpression) == nil {
+                return "email: must match ^[^@]+@[^@]+$"
+            }
+        }
+        if let v = self.workEmail {
+            if v.range(of: "^[^@]+@[^@]+$", options: .regularExpression) == nil {
+                return "workEmail: must match ^[^@]+@[^@]+$"
+            }
+            if v.unicodeScalars.count > 254 {
+                return "workEmail: length must be <= 254"
+            }
+        }
+        do {
+            let v = self.companyEmail ?? ""
+            if v.range(of: "^[^@]+@[^@]+$", options: .regularExpression) == nil {
+                return "companyEmail: must match ^[^@]+@[^@]+$"
+            }
+            if v.range(of: "@example[.]com$", options: .regularExpression) == nil {
+                return "companyEmail: must match @example[.]com$"
+            }
+        }
+        do {
+            let v = self.price
+            if v < 0 {
+                return "price: must be >= 0"
+            }
+        }
+        return nil
+    }
+}
+
+// TestMaps has maps with typed keys.
+@objcMembers class TestMaps: Pring.Object {
+    var counts: [TestEnum: Int] = [:]
+    dynamic var directions: [String: TestDirection] = [:]
+    var structs: [TestDirection: TestStruct]?
+
+    override func encode(_ key: String, value: Any?) -> Any? {
+        switch key {
+        case "counts":
+            let map = self.counts
+            var data: [String: Any] = [:]
+            for (key, item) in map {
+                if let key = key.firestoreValue {
+                    data[key] = item
+                }
+            }
+            return data
+        case "structs":
+            guard let map = self.structs else {
+                return nil
+            }
+            var data: [String: Any] = [:]
+            for (key, item) in map {
+                if let key = key.firestoreValue {
+                    data[key] = item.rawValue
+                }
+            }
+            return data
+        default:
+            break
+        }
+        return nil
+    }
+
+    override func decode(_ key: String, value: Any?) -> Bool {
+        switch key {
+        case "counts":
+            guard let value = value as? [String: Any] else {
+                return false
+            }
+            var map: [TestEnum: Int] = [:]
+            for (key, item) in value {
+                if let item = item as? Int, let key = TestEnum(firestoreValue: key) {
+                    map[key] = item
+                }
+            }
+            self.counts = map
+            return true
+        case "structs":
+            guard let value = value as? [String: Any] else {
+                return false
+            }
+            var map: [TestDirection: TestStruct] = [:]
+            for (key, item) in value {
+                if let item = (item as? [String: Any]).map({ TestStruct(id: "structs.\(key)", value: $0) }), let key = TestDirection(firestoreValue: key) {
+                    map[key] = item
+                }
+            }
+            self.structs = map
+            return true
+        default:
+            break
+        }
+        return false
+    }
+
+    // Returns a description of the first validation constraint that the object violates, if any.
+    func validate() -> String? {
+        if let v = self.structs {
+            if v.isEmpty {
+                return "structs: must not be empty"
+            }
+        }
+        return nil
+    }
+}
+
+// TestReserved has removed and deprecated fields.
+@objcMembers class TestReserved: Pring.Object {
+    // The display name.
+    @available(*, deprecated)
+    dynamic var name: String?
+    @available(*, deprecated)
+    dynamic var title: String?
+    dynamic var logins: Int = 0
 }
 
 @objcMembers class TestTimestamps: Pring.Object {
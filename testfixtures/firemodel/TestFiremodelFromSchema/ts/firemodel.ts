@@ -10,6 +10,59 @@ export namespace example {
     mimeType: string;
     name: string;
   }
+
+  /** TestEmail is an email address. */
+  export type TestEmail = string & { readonly __brand: 'TestEmail' };
+  export type TestCents = number & { readonly __brand: 'TestCents' };
+  export type TestWorkEmail = string & { readonly __brand: 'TestWorkEmail' };
+  export type TestTags = string[] & { readonly __brand: 'TestTags' };
+  export type TestCreatedAt = firestore.Timestamp & { readonly __brand: 'TestCreatedAt' };
+
+  /** TestDirection is stored with explicit values. */
+  export enum TestDirection {
+    north = 'N',
+    south = 'S',
+    /**
+     * Replaced by south.
+     * @deprecated
+     */
+    down = 'D',
+  }
+
+  /** Returns the TestDirection stored as value, or one of its aliases. */
+  export function parseTestDirection(value: string): TestDirection | undefined {
+    switch (value) {
+      case 'N':
+        return TestDirection.north;
+      case 'S':
+      case 'SOUTH':
+      case 'DOWN':
+        return TestDirection.south;
+      case 'D':
+        return TestDirection.down;
+      default:
+        return undefined;
+    }
+  }
+
+  /** TestPriority is stored as integers. */
+  export enum TestPriority {
+    low = 1,
+    high = 2,
+  }
+
+  /** Returns the TestPriority stored as value, or one of its aliases. */
+  export function parseTestPriority(value: number): TestPriority | undefined {
+    switch (value) {
+      case 1:
+        return TestPriority.low;
+      case 2:
+      case 3:
+        return TestPriority.high;
+      default:
+        return undefined;
+    }
+  }
   export enum TestEnum {
     left = 'LEFT',
     right = 'RIGHT',
@@ -22,6 +75,76 @@ export namespace example {
     someEnum?: TestEnum;
   }
 
+  /** Returns a new ITestStruct with default values. */
+  export function newTestStruct(): Partial<ITestStruct> {
+    return {
+      someEnum: TestEnum.left,
+    };
+  }
+
+  /** Returns a description of the first validation constraint that m violates, if any. */
+  export function validateTestStruct(m: ITestStruct): string | undefined {
+    {
+      const v = m.howMuch || 0;
+      if (v < 0) {
+        return 'howMuch: must be >= 0';
+      }
+    }
+    return undefined;
+  }
+  export interface ITestCard {
+    number?: string;
+  }
+  export interface ITestBankTransfer {
+    iban?: string;
+  }
+
+  /** TestOwned is shared by documents that have an owner. */
+  export interface ITestOwned {
+    ownerId?: string;
+    version?: number;
+  }
+
+  /** Returns a new ITestOwned with default values. */
+  export function newTestOwned(): Partial<ITestOwned> {
+    return {
+      version: 1,
+    };
+  }
+  export interface ITestAudited {
+    updatedBy?: string;
+  }
+
+  /** Returns a description of the first validation constraint that m violates, if any. */
+  export function validateTestAudited(m: ITestAudited): string | undefined {
+    {
+      const v = m.updatedBy || '';
+      if (Array.from(v).length < 1) {
+        return 'updatedBy: length must be >= 1';
+      }
+    }
+    return undefined;
+  }
+  export interface ITestLineItem extends ITestAudited {
+    sku?: string;
+  }
+
+  /** Returns a description of the first validation constraint that m violates, if any. */
+  export function validateTestLineItem(m: ITestLineItem): string | undefined {
+    {
+      const v = m.updatedBy || '';
+      if (Array.from(v).length < 1) {
+        return 'updatedBy: length must be >= 1';
+      }
+    }
+    return undefined;
+  }
+
+  /** TestPayment is paid by card or by bank transfer. */
+  export type TestPayment =
+    | { kind: 'card'; card: ITestCard; }
+    | { kind: 'bankTransfer'; bankTransfer: ITestBankTransfer; };
+
   /** A Test is a test model. */
   export interface ITestModel {
     nestedCollection: firestore.CollectionReference<ITestModel>;
@@ -53,12 +176,320 @@ export namespace example {
     testFile?: IFile;
     url?: URL;
     nested?: ITestStruct;
+    /** Stored under a legacy key. */
+    LEGACY_name?: string;
+    /** Stored under a key that is a Swift keyword. */
+    default?: TestEnum;
 
     /** Record creation timestamp. */
     createdAt?: firestore.Timestamp;
     /** Record update timestamp. */
     updatedAt?: firestore.Timestamp;
   }
+
+  /** Returns a description of the first validation constraint that m violates, if any. */
+  export function validateTestModel(m: ITestModel): string | undefined {
+    {
+      const v = m.models || [];
+      for (let i = 0; i < v.length; i++) {
+        const err = validateTestStruct(v[i]);
+        if (err !== undefined) {
+          return `models[${i}].${err}`;
+        }
+      }
+    }
+    {
+      const v = m.models2 || [];
+      for (let i = 0; i < v.length; i++) {
+        const err = validateTestStruct(v[i]);
+        if (err !== undefined) {
+          return `models2[${i}].${err}`;
+        }
+      }
+    }
+    if (m.nested != null) {
+      const v = m.nested;
+      const err = validateTestStruct(v);
+      if (err !== undefined) {
+        return `nested.${err}`;
+      }
+    }
+    return undefined;
+  }
+
+  /** TestSnakeCase is stored with snake_case keys. */
+  export interface ITestSnakeCase {
+    display_name?: string;
+    logins?: number;
+  }
+
+  /** TestPresence has fields with presence modifiers. */
+  export interface ITestPresence {
+    name: string;
+    age?: number;
+    nickname?: string | null;
+    profile: ITestStruct;
+    previousProfile?: ITestStruct | null;
+    labels?: { [key: string]: string; };
+    avatar: firestore.Blob;
+    active: boolean;
+  }
+
+  /** Returns a description of the first validation constraint that m violates, if any. */
+  export function validateTestPresence(m: ITestPresence): string | undefined {
+    {
+      const v = m.profile;
+      const err = validateTestStruct(v);
+      if (err !== undefined) {
+        return `profile.${err}`;
+      }
+    }
+    if (m.previousProfile != null) {
+      const v = m.previousProfile;
+      const err = validateTestStruct(v);
+      if (err !== undefined) {
+        return `previousProfile.${err}`;
+      }
+    }
+    return undefined;
+  }
+
+  /** TestDefaults has fields with default values. */
+  export interface ITestDefaults {
+    retries?: number;
+    ratio?: number;
+    enabled?: boolean;
+    label: string;
+    homepage?: URL;
+    direction?: TestEnum;
+  }
+
+  /** Returns a new ITestDefaults with default values. */
+  export function newTestDefaults(): Partial<ITestDefaults> {
+    return {
+      retries: 3,
+      ratio: 0.5,
+      enabled: true,
+      label: 'it\'s new',
+      homepage: 'https://example.com',
+      direction: TestEnum.up,
+    };
+  }
+
+  /** TestEnums has fields with explicitly stored enums. */
+  export interface ITestEnums {
+    direction?: TestDirection;
+    priority?: TestPriority;
+    priorities?: TestPriority[];
+  }
+
+  /** Returns a new ITestEnums with default values. */
+  export function newTestEnums(): Partial<ITestEnums> {
+    return {
+      direction: TestDirection.north,
+      priority: TestPriority.high,
+    };
+  }
+
+  /** TestValidation has fields with validation constraints. */
+  export interface ITestValidation {
+    name: string;
+    age?: number;
+    ratio?: number;
+    tags?: string[];
+    labels?: { [key: string]: string; };
+    nested: ITestStruct;
+    nestedList?: ITestStruct[];
+  }
+
+  /** Returns a description of the first validation constraint that m violates, if any. */
+  export function validateTestValidation(m: ITestValidation): string | undefined {
+    {
+      const v = m.name;
+      if (Array.from(v).length < 1) {
+        return 'name: length must be >= 1';
+      }
+      if (Array.from(v).length > 64) {
+        return 'name: length must be <= 64';
+      }
+      if (!new RegExp('^[a-z]+$').test(v)) {
+        return 'name: must match ^[a-z]+$';
+      }
+    }
+    if (m.age != null) {
+      const v = m.age;
+      if (v < 0) {
+        return 'age: must be >= 0';
+      }
+      if (v > 150) {
+        return 'age: must be <= 150';
+      }
+    }
+    {
+      const v = m.ratio || 0;
+      if (v < -1.5) {
+        return 'ratio: must be >= -1.5';
+      }
+      if (v > 1.5) {
+        return 'ratio: must be <= 1.5';
+      }
+    }
+    if (m.tags != null) {
+      const v = m.tags;
+      if (v.length === 0) {
+        return 'tags: must not be empty';
+      }
+      if (v.length > 10) {
+        return 'tags: size must be <= 10';
+      }
+    }
+    {
+      const v = m.labels || {};
+      if (Object.keys(v).length === 0) {
+        return 'labels: must not be empty';
+      }
+    }
+    {
+      const v = m.nested;
+      const err = validateTestStruct(v);
+      if (err !== undefined) {
+        return `nested.${err}`;
+      }
+    }
+    {
+      const v = m.nestedList || [];
+      for (let i = 0; i < v.length; i++) {
+        const err = validateTestStruct(v[i]);
+        if (err !== undefined) {
+          return `nestedList[${i}].${err}`;
+        }
+      }
+    }
+    return undefined;
+  }
+
+  /** TestUnions has fields that hold unions. */
+  export interface ITestUnions {
+    payment?: TestPayment;
+    lastPayment: TestPayment;
+    payments?: TestPayment[];
+  }
+
+  /** TestInvoice extends shared structs. */
+  export interface ITestInvoice extends ITestOwned, ITestAudited {
+    total?: number;
+    items?: ITestLineItem[];
+  }
+
+  /** Returns a new ITestInvoice with default values. */
+  export function newTestInvoice(): Partial<ITestInvoice> {
+    return {
+      version: 1,
+    };
+  }
+
+  /** Returns a description of the first validation constraint that m violates, if any. */
+  export function validateTestInvoice(m: ITestInvoice): string | undefined {
+    {
+      const v = m.updatedBy || '';
+      if (Array.from(v).length < 1) {
+        return 'updatedBy: length must be >= 1';
+      }
+    }
+    {
+      const v = m.items || [];
+      for (let i = 0; i < v.length; i++) {
+        const err = validateTestLineItem(v[i]);
+        if (err !== undefined) {
+          return `items[${i}].${err}`;
+        }
+      }
+    }
+    return undefined;
+  }
+
+  /** TestAliases has fields declared with type aliases. */
+  export interface ITestAliases {
+    email?: TestEmail;
+    workEmail?: TestWorkEmail;
+    /** Checked against the pattern of TestEmail and its own. */
+    companyEmail?: TestEmail;
+    price?: TestCents;
+    tags?: TestTags;
+    created?: TestCreatedAt;
+  }
+
+  /** Returns a new ITestAliases with default values. */
+  export function newTestAliases(): Partial<ITestAliases> {
+    return {
+      price: 100 as TestCents,
+    };
+  }
+
+  /** Returns a description of the first validation constraint that m violates, if any. */
+  export function validateTestAliases(m: ITestAliases): string | undefined {
+    {
+      const v = m.email || '';
+      if (!new RegExp('^[^@]+@[^@]+$').test(v)) {
+        return 'email: must match ^[^@]+@[^@]+$';
+      }
+    }
+    if (m.workEmail != null) {
+      const v = m.workEmail;
+      if (!new RegExp('^[^@]+@[^@]+$').test(v)) {
+        return 'workEmail: must match ^[^@]+@[^@]+$';
+      }
+      if (Array.from(v).length > 254) {
+        return 'workEmail: length must be <= 254';
+      }
+    }
+    {
+      const v = m.companyEmail || '';
+      if (!new RegExp('^[^@]+@[^@]+$').test(v)) {
+        return 'companyEmail: must match ^[^@]+@[^@]+$';
+      }
+      if (!new RegExp('@example[.]com$').test(v)) {
+        return 'companyEmail: must match @example[.]com$';
+      }
+    }
+    {
+      const v = m.price || 0;
+      if (v < 0) {
+        return 'price: must be >= 0';
+      }
+    }
+    return undefined;
+  }
+
+  /** TestMaps has maps with typed keys. */
+  export interface ITestMaps {
+    counts?: { [key in TestEnum]?: number; };
+    directions?: { [key: string]: TestDirection; };
+    structs?: { [key in TestDirection]?: ITestStruct; };
+  }
+
+  /** Returns a description of the first validation constraint that m violates, if any. */
+  export function validateTestMaps(m: ITestMaps): string | undefined {
+    if (m.structs != null) {
+      const v = m.structs;
+      if (Object.keys(v).length === 0) {
+        return 'structs: must not be empty';
+      }
+    }
+    return undefined;
+  }
+
+  /** TestReserved has removed and deprecated fields. */
+  export interface ITestReserved {
+    /**
+     * The display name.
+     * @deprecated
+     */
+    name?: string;
+    /** @deprecated */
+    title?: string;
+    logins?: number;
+  }
   export interface ITestTimestamps {
 
     /** Record creation timestamp. */
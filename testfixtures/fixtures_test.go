@@ -33,3 +33,18 @@ func TestRegexPath(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateOptionalCollections(t *testing.T) {
+	valid := func() *firemodels.TestValidation {
+		return &firemodels.TestValidation{Name: "abc", Labels: map[string]string{"a": "b"}}
+	}
+
+	// Optional collections are only validated when they are set.
+	assert.NilError(t, valid().Validate())
+	assert.NilError(t, (&firemodels.TestMaps{}).Validate())
+
+	m := valid()
+	m.Tags = []string{}
+	assert.Error(t, m.Validate(), "tags: must not be empty")
+	assert.Error(t, (&firemodels.TestMaps{Structs: map[firemodels.TestDirection]*firemodels.TestStruct{}}).Validate(), "structs: must not be empty")
+}